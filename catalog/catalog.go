@@ -0,0 +1,114 @@
+package catalog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/emmahsax/go-games/registry"
+	"github.com/spf13/cobra"
+)
+
+// NewListCommand builds the command that prints every game in a table
+func NewListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List every game you can play (alias: ls)",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printList(cmd.OutOrStdout(), registry.Games())
+		},
+	}
+
+	return cmd
+}
+
+// NewInfoCommand builds the command that prints everything about one game, including how to play it
+func NewInfoCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "info <game>",
+		Short: "Show the description and controls of a game",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			game, ok := registry.Lookup(args[0])
+			if !ok {
+				return fmt.Errorf("unknown game %q, run go-games list to see every game", args[0])
+			}
+
+			return printInfo(cmd.OutOrStdout(), game)
+		},
+	}
+
+	return cmd
+}
+
+func printList(out io.Writer, games []registry.Game) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "GAME\tALIASES\tTITLE\tPLAYERS\tVERSION\tTAGS")
+	for _, game := range games {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			game.Name(),
+			orDash(strings.Join(game.Aliases(), ", ")),
+			game.Metadata.Title,
+			game.Metadata.Players(),
+			orDash(game.Metadata.Version),
+			orDash(strings.Join(game.Metadata.Tags, ", ")),
+		)
+	}
+
+	return w.Flush()
+}
+
+func printInfo(out io.Writer, game registry.Game) error {
+	meta := game.Metadata
+
+	fmt.Fprintln(out, meta.Title)
+	fmt.Fprintln(out, strings.Repeat("=", len(meta.Title)))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, meta.Description)
+	fmt.Fprintln(out)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Command:\tgo-games %s\n", game.Name())
+	if len(game.Aliases()) > 0 {
+		fmt.Fprintf(w, "Aliases:\t%s\n", strings.Join(game.Aliases(), ", "))
+	}
+	fmt.Fprintf(w, "Players:\t%s\n", meta.Players())
+	if meta.Version != "" {
+		fmt.Fprintf(w, "Version:\t%s\n", meta.Version)
+	}
+	if meta.Author != "" {
+		fmt.Fprintf(w, "Author:\t%s\n", meta.Author)
+	}
+	if len(meta.Tags) > 0 {
+		fmt.Fprintf(w, "Tags:\t%s\n", strings.Join(meta.Tags, ", "))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(meta.Controls) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Controls:")
+
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, control := range meta.Controls {
+			fmt.Fprintf(w, "  %s\t%s\n", control.Keys, control.Action)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
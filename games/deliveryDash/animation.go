@@ -0,0 +1,111 @@
+package deliveryDash
+
+import (
+	"math"
+
+	"github.com/emmahsax/go-games/games/deliveryDash/sim"
+)
+
+const (
+	turnTicks    = 6    // Ticks a car takes to turn to face a new way
+	bumpTicks    = 8    // Ticks a car takes to bounce off a wall it drove into
+	bumpDistance = 0.15 // How far a car bounces toward the wall, in cells
+)
+
+// carAnimation eases a car's sprite from cell to cell over the cooldown between its moves, and turns it the short way
+// round, while the world itself still moves the car a whole cell at a time. Everything is timed in the world's ticks,
+// so it stops along with the world when the game is paused.
+type carAnimation struct {
+	started      bool
+	cellX, cellY int           // Cell the car was in when the animation last caught up with it
+	fromX, fromY float64       // Where the sprite was, in cells, when the car last moved
+	moved        int           // Tick the car last moved on
+	moveTicks    int           // Ticks the last move is eased over
+	fromAngle    float64       // Way the sprite was facing, in degrees, when the car last turned
+	toAngle      float64       // Way the sprite is turning to face, which can be past 360 to turn the short way
+	turned       int           // Tick the car last turned on
+	bumped       int           // Tick the car last bumped into something, or 0
+	bumpDir      sim.Direction // Way the car was going when it bumped
+}
+
+// follow catches the animation up with a car as of a tick, easing any move it's made over moveTicks. Jumps of more
+// than one cell, like a new run starting, aren't animated.
+func (a *carAnimation) follow(car sim.Car, tick, moveTicks int) {
+	distance := math.Abs(float64(car.CellX-a.cellX)) + math.Abs(float64(car.CellY-a.cellY))
+	if !a.started || distance > 1 {
+		angle := carRotation(car.Direction)
+		*a = carAnimation{
+			started:   true,
+			cellX:     car.CellX,
+			cellY:     car.CellY,
+			fromX:     float64(car.CellX),
+			fromY:     float64(car.CellY),
+			fromAngle: angle,
+			toAngle:   angle,
+		}
+		return
+	}
+
+	if distance == 1 {
+		a.fromX, a.fromY = a.position(tick)
+		a.cellX, a.cellY = car.CellX, car.CellY
+		a.moved, a.moveTicks = tick, moveTicks
+	}
+
+	if target := carRotation(car.Direction); target != normalizeAngle(a.toAngle) {
+		a.fromAngle = normalizeAngle(a.rotation(tick))
+		a.toAngle = a.fromAngle + normalizeAngle(target-a.fromAngle+180) - 180 // Turning at most half way round
+		a.turned = tick
+	}
+}
+
+// bump starts the car bouncing off whatever it drove into on a tick
+func (a *carAnimation) bump(tick int, dir sim.Direction) {
+	a.bumped, a.bumpDir = tick, dir
+}
+
+// position returns where the sprite is at a tick, in cells, including any bounce off a wall
+func (a *carAnimation) position(tick int) (float64, float64) {
+	eased := easeOut(progress(tick-a.moved, a.moveTicks))
+	x := a.fromX + (float64(a.cellX)-a.fromX)*eased
+	y := a.fromY + (float64(a.cellY)-a.fromY)*eased
+
+	if a.bumped > 0 && tick-a.bumped < bumpTicks {
+		bounce := bumpDistance * math.Sin(math.Pi*progress(tick-a.bumped, bumpTicks))
+		switch a.bumpDir {
+		case sim.Up:
+			y -= bounce
+		case sim.Right:
+			x += bounce
+		case sim.Down:
+			y += bounce
+		case sim.Left:
+			x -= bounce
+		}
+	}
+
+	return x, y
+}
+
+// rotation returns the way the sprite is facing at a tick, in degrees
+func (a *carAnimation) rotation(tick int) float64 {
+	return a.fromAngle + (a.toAngle-a.fromAngle)*easeOut(progress(tick-a.turned, turnTicks))
+}
+
+// normalizeAngle returns the same way as an angle in degrees, from 0 up to 360
+func normalizeAngle(angle float64) float64 {
+	return math.Mod(math.Mod(angle, 360)+360, 360)
+}
+
+// progress returns how far through something that takes a number of ticks it is, from 0 to 1
+func progress(elapsed, ticks int) float64 {
+	if ticks <= 0 {
+		return 1
+	}
+	return min(max(float64(elapsed)/float64(ticks), 0), 1)
+}
+
+// easeOut starts quickly and slows down toward the end, like a car pulling up
+func easeOut(t float64) float64 {
+	return 1 - (1-t)*(1-t)
+}
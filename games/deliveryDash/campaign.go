@@ -0,0 +1,131 @@
+package deliveryDash
+
+import (
+	"embed"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/emmahsax/go-games/games/deliveryDash/sim"
+	"github.com/emmahsax/go-games/storage"
+)
+
+// The campaign is the levels bundled with the game, played in order: each level unlocks once the one before it has
+// been delivered. Levels are named so that they sort in the order they're played (e.g. 01-first-day.json).
+//
+//go:embed levels/*.json
+var campaignFiles embed.FS
+
+// campaignLevels returns the levels of the campaign, in the order they're played
+func campaignLevels() ([]*sim.Level, error) {
+	entries, err := campaignFiles.ReadDir("levels")
+	if err != nil {
+		return nil, err
+	}
+
+	var levels []*sim.Level
+	for _, entry := range entries {
+		data, err := campaignFiles.ReadFile(path.Join("levels", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		level, err := sim.ParseLevel(data)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+
+	return levels, nil
+}
+
+// levelDifficulty returns the difficulty a level is played on, unless the player picks another
+func levelDifficulty(level *sim.Level) sim.Difficulty {
+	if preset, ok := sim.DifficultyPreset(level.Difficulty); ok {
+		return preset
+	}
+	return sim.DefaultConfig().Difficulty
+}
+
+// levelLeaderboard returns the name of a level as it's used in the names of leaderboards (e.g. level-first-day)
+func levelLeaderboard(level *sim.Level) string {
+	return "level-" + strings.ReplaceAll(strings.ToLower(level.Name), " ", "-")
+}
+
+// campaignProgress is how far through the campaign the player has got, which is kept between games
+type campaignProgress struct {
+	Levels map[string]levelRecord `json:"levels"` // Every level delivered so far, by name
+}
+
+// levelRecord is the best a level of the campaign has been played
+type levelRecord struct {
+	Best    time.Duration `json:"best"`    // Fastest delivery
+	BeatPar bool          `json:"beatPar"` // Whether the level has been delivered within its par time
+}
+
+// loadProgress reads how far through the campaign the player has got, which is nowhere if they've never played it
+func loadProgress() (*campaignProgress, error) {
+	path, err := progressPath()
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &campaignProgress{}
+	if err := storage.Load(path, progress); err != nil {
+		return nil, err
+	}
+	if progress.Levels == nil {
+		progress.Levels = make(map[string]levelRecord)
+	}
+
+	return progress, nil
+}
+
+// save writes how far through the campaign the player has got to disk
+func (p *campaignProgress) save() error {
+	path, err := progressPath()
+	if err != nil {
+		return err
+	}
+
+	return storage.Save(path, p)
+}
+
+// record marks a level as delivered in a time, keeping the best time and whether par has ever been beaten
+func (p *campaignProgress) record(level *sim.Level, elapsed time.Duration) {
+	record, played := p.Levels[level.Name]
+	if !played || elapsed < record.Best {
+		record.Best = elapsed
+	}
+	record.BeatPar = record.BeatPar || beatPar(level, elapsed)
+	p.Levels[level.Name] = record
+}
+
+// unlocked reports whether a level of the campaign can be played: the first always can, and every other once the one
+// before it has been delivered
+func (p *campaignProgress) unlocked(levels []*sim.Level, i int) bool {
+	if i == 0 {
+		return true
+	}
+	_, delivered := p.Levels[levels[i-1].Name]
+	return delivered
+}
+
+// next returns the first level of the campaign that hasn't been delivered yet, or the last level once they all have
+func (p *campaignProgress) next(levels []*sim.Level) int {
+	for i, level := range levels {
+		if _, delivered := p.Levels[level.Name]; !delivered {
+			return i
+		}
+	}
+	return len(levels) - 1
+}
+
+// beatPar reports whether a delivery was made within a level's par time
+func beatPar(level *sim.Level, elapsed time.Duration) bool {
+	return level.Par > 0 && elapsed.Seconds() <= level.Par
+}
+
+func progressPath() (string, error) {
+	return storage.Path("campaign", gameName+".json")
+}
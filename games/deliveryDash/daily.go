@@ -0,0 +1,216 @@
+package deliveryDash
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/emmahsax/go-games/games/deliveryDash/sim"
+	"github.com/emmahsax/go-games/scores"
+	"github.com/emmahsax/go-games/storage"
+	"github.com/spf13/cobra"
+)
+
+// The daily challenge is a city everyone plays on the same day: its seed and difficulty come from the date in UTC, so
+// nobody has to share a seed. Each player's first attempt of the day is ranked and kept in their history, and any
+// attempts after it are practice.
+
+// dailyDate returns the date of the daily challenge at a time (e.g. 2006-01-02)
+func dailyDate(now time.Time) string {
+	return now.UTC().Format(time.DateOnly)
+}
+
+// dailySeed returns the seed of the daily challenge on a date
+func dailySeed(date string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(gameName + " " + date))
+	return int64(hash.Sum64())
+}
+
+// dailyDifficulty returns the preset the daily challenge is played on on a date, which changes from day to day
+func dailyDifficulty(date string) sim.Difficulty {
+	names := sim.Difficulties()
+	preset, _ := sim.DifficultyPreset(names[(uint64(dailySeed(date))>>32)%uint64(len(names))])
+	return preset
+}
+
+// dailyHistory is every ranked attempt at the daily challenge made on this computer
+type dailyHistory struct {
+	Attempts []dailyAttempt `json:"attempts"`
+}
+
+// dailyAttempt is a player's ranked attempt at the daily challenge of a date. An attempt that was neither delivered nor
+// failed was abandoned part of the way through.
+type dailyAttempt struct {
+	Date       string        `json:"date"`
+	Player     string        `json:"player"`
+	Difficulty string        `json:"difficulty"`
+	Time       time.Duration `json:"time"`
+	Moves      int           `json:"moves"`
+	Delivered  bool          `json:"delivered"`
+	Failure    sim.Failure   `json:"failure,omitempty"` // Why the attempt failed, if it did
+}
+
+// loadDailyHistory reads every ranked attempt at the daily challenge, which is none if it's never been played
+func loadDailyHistory() (*dailyHistory, error) {
+	path, err := dailyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	history := &dailyHistory{}
+	if err := storage.Load(path, history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// save writes the history to disk
+func (h *dailyHistory) save() error {
+	path, err := dailyPath()
+	if err != nil {
+		return err
+	}
+
+	return storage.Save(path, h)
+}
+
+// attempt returns a player's ranked attempt at the daily challenge of a date, and false if they haven't made one
+func (h *dailyHistory) attempt(player, date string) (dailyAttempt, bool) {
+	for _, attempt := range h.Attempts {
+		if attempt.Player == player && attempt.Date == date {
+			return attempt, true
+		}
+	}
+	return dailyAttempt{}, false
+}
+
+// record keeps a ranked attempt, replacing the one the player already has for its date as the attempt goes on
+func (h *dailyHistory) record(attempt dailyAttempt) {
+	for i, other := range h.Attempts {
+		if other.Player == attempt.Player && other.Date == attempt.Date {
+			h.Attempts[i] = attempt
+			return
+		}
+	}
+	h.Attempts = append(h.Attempts, attempt)
+}
+
+// playerAttempts returns a player's ranked attempts, newest first
+func (h *dailyHistory) playerAttempts(player string) []dailyAttempt {
+	var attempts []dailyAttempt
+	for _, attempt := range h.Attempts {
+		if attempt.Player == player {
+			attempts = append(attempts, attempt)
+		}
+	}
+	sort.Slice(attempts, func(i, j int) bool { return attempts[i].Date > attempts[j].Date })
+	return attempts
+}
+
+// streaks returns the number of days in a row a player has delivered the daily challenge up to today (or yesterday,
+// while today's is still to play), and the most days in a row they ever have
+func (h *dailyHistory) streaks(player, today string) (int, int) {
+	delivered := make(map[string]bool)
+	for _, attempt := range h.playerAttempts(player) {
+		if attempt.Delivered {
+			delivered[attempt.Date] = true
+		}
+	}
+
+	day := func(date string, offset int) string {
+		t, _ := time.Parse(time.DateOnly, date)
+		return t.AddDate(0, 0, offset).Format(time.DateOnly)
+	}
+
+	current := 0
+	date := today
+	if !delivered[date] {
+		date = day(date, -1)
+	}
+	for delivered[date] {
+		current++
+		date = day(date, -1)
+	}
+
+	longest := 0
+	for date := range delivered {
+		if delivered[day(date, -1)] {
+			continue // Not the start of a streak
+		}
+		streak := 0
+		for delivered[date] {
+			streak++
+			date = day(date, 1)
+		}
+		longest = max(longest, streak)
+	}
+
+	return current, longest
+}
+
+// result describes how an attempt went (e.g. 12.34s)
+func (a dailyAttempt) result() string {
+	switch {
+	case a.Delivered:
+		return scores.FormatTime(a.Time)
+	case a.Failure != sim.NotFailed:
+		return "Failed: " + failureMessage(a.Failure)
+	default:
+		return "Abandoned"
+	}
+}
+
+func dailyPath() (string, error) {
+	return storage.Path("daily", gameName+".json")
+}
+
+// printDailyHistory writes a player's streaks and ranked attempts at the daily challenge as a table
+func printDailyHistory(out io.Writer, history *dailyHistory, player string, now time.Time) error {
+	today := dailyDate(now)
+	current, longest := history.streaks(player, today)
+
+	fmt.Fprintf(out, "Daily challenges of %s\n", player)
+	fmt.Fprintf(out, "Current streak: %d days   Longest streak: %d days\n", current, longest)
+	if _, played := history.attempt(player, today); !played {
+		fmt.Fprintf(out, "Today's challenge (%s) is still to play, with go-games delivery-dash --daily\n", difficultyTitle(dailyDifficulty(today).Name()))
+	}
+
+	attempts := history.playerAttempts(player)
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tDIFFICULTY\tRESULT\tMOVES")
+	for _, attempt := range attempts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", attempt.Date, difficultyTitle(attempt.Difficulty), attempt.result(), attempt.Moves)
+	}
+	return w.Flush()
+}
+
+// newHistoryCommand returns the command that shows a player's history of daily challenges
+func newHistoryCommand() *cobra.Command {
+	var player string
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show your streaks and previous times at the daily challenge",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			history, err := loadDailyHistory()
+			if err != nil {
+				return err
+			}
+			return printDailyHistory(cmd.OutOrStdout(), history, player, time.Now())
+		},
+	}
+
+	cmd.Flags().StringVar(&player, "player", scores.DefaultPlayer(), "Name to show the history of")
+
+	return cmd
+}
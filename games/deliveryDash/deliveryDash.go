@@ -1,60 +1,20 @@
 package deliveryDash
 
-// # Delivery Dash: A Dynamic Maze Delivery Challenge 🚗📦
-
-// Introducing Delivery Dash, a fast-paced maze game where you play as a delivery driver navigating through a chaotic, ever-changing city. This isn't your typical maze game - the walls shift beneath your wheels and your delivery destination keeps moving!
-
-// ## Key Features
-// - **Dynamic Environment**: The maze walls constantly shift and change, creating a unique challenge every time
-// - **Moving Target**: Your delivery destination moves along the bottom of the maze, requiring quick thinking and adaptable strategy
-// - **Precision Controls**: One-press-one-move mechanics that reward careful planning and tactical movement
-// - **Time Challenge**: Race against the clock to make your delivery as quickly as possible
-// - **Permanent Obstacles**: Strategic permanent walls in the center and diagonals create consistent navigation challenges
-
-// ## Gameplay
-// Players start at the top of the maze and must navigate to a moving delivery point at the bottom. The challenge comes from:
-// - Planning routes through shifting walls
-// - Tracking the moving delivery point
-// - Making precise movements under pressure
-// - Finding the optimal path while the maze changes around you
-
-// ## Technical Highlights
-// - Built with Ebitengine for smooth 2D graphics
-// - Implements smart pathfinding to prevent player entrapment
-// - Efficient maze generation and update algorithms
-// - Clean, modular code design for easy maintenance and future enhancements
-
-// ## How to Play
-// - Use arrow keys or WASD to move
-// - Each key press moves one cell
-// - Green square marks the start
-// - Blue square marks the moving delivery point
-// - Timer starts when you enter the maze
-// - Press ESC to exit at any time
-
-// ## Command Line Usage
-// ```bash
-// # Start the game using any of these commands:
-// go-games delivery-dash
-// go-games dd
-// ```
-
-// ## Development Notes
-// - Generated with AI assistance from Cursor on April 24, 2025
-// - Collaborative development with @emmahsax
-// - Focused on balancing challenge and fairness
-// - Implements robust wall protection system to prevent soft-locks
-
-// Perfect for players who enjoy dynamic puzzle-solving and quick-thinking challenges! 🎮
+// Delivery Dash is a maze game where you drive through a city whose walls keep shifting to deliver to a customer who
+// keeps moving. Run go-games delivery-dash --help to see every way to play it.
 
 import (
 	"fmt"
 	"image/color"
 	"math"
-	"math/rand"
 	"strings"
 	"time"
 
+	"github.com/emmahsax/go-games/games/deliveryDash/sim"
+	"github.com/emmahsax/go-games/input"
+	"github.com/emmahsax/go-games/registry"
+	"github.com/emmahsax/go-games/scores"
+	"github.com/emmahsax/go-games/terminal"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -62,60 +22,119 @@ import (
 )
 
 const (
-	cellSize           = 40                          // Size of each cell in the maze
-	mazeWidth          = 20                          // Number of cells wide
-	mazeHeight         = 15                          // Number of cells tall
-	wallThickness      = 2                           // Thickness of maze walls
-	borderSize         = cellSize                    // Size of the border around the maze
-	screenWidth        = cellSize * (mazeWidth + 2)  // Add 2 cells for borders
-	screenHeight       = cellSize * (mazeHeight + 2) // Add 2 cells for borders
-	mazeUpdateInterval = 0.05                        // Seconds between maze updates (20 times per second)
-	wallUpdateInterval = 0.25                        // Seconds between wall updates (4 times per second)
-	moveCooldown       = 10                          // Frames between allowed movements
-	wallChangeChance   = 0.75                        // Chance for each wall to change (75%)
+	defaultCellSize       = 40              // Size of each cell in the maze, unless configured otherwise
+	minCellSize           = 8               // Smallest cells that still fit the car and walls
+	maxCellSize           = 100             // Largest cells
+	spriteCellSize        = 40              // Cell size the car sprite is drawn for, so it can be scaled to others
+	wallThickness         = 2               // Thickness of maze walls
+	gaugeWidth            = 120             // Width of the fuel and deadline bars
+	windowMargin          = 0.9             // Most of the monitor the window may cover before it's scaled down to fit
+	titleScreenLineLength = 60              // Characters per line of title screen text
+	gameName              = "delivery-dash" // Name people use on the command-line, and to store scores under
+	shiftLeaderboard      = "shift"         // Start of the names of the leaderboards shifts are ranked on
+	dailyLeaderboard      = "daily"         // Start of the names of the leaderboards of each day's daily challenge
 )
 
-type Direction int
+var Metadata = registry.Metadata{
+	Title: "Delivery Dash",
+	Description: "You are a delivery driver in a chaotic, ever-changing city. " +
+		"The walls are shifting beneath your wheels and the customer keeps moving! " +
+		"Navigate through the maze to deliver your package as fast as possible, before the walls trap you!",
+	Controls: []registry.Control{
+		{Keys: "Arrow keys / WASD / D-pad", Action: "Move one cell"},
+		{Keys: "E", Action: "Use the power-up you're carrying"},
+		{Keys: "SPACE / ENTER", Action: "Start the game"},
+		{Keys: "Left / Right", Action: "Pick the difficulty (or level) on the title screen"},
+		{Keys: "R / Shift+R", Action: "Play again on a new maze / retry the same seed, once the run is over"},
+		{Keys: "ESC / P", Action: "Pause to resume, restart, or exit (ESC exits from the title screen)"},
+	},
+	MinPlayers: 1,
+	MaxPlayers: 1,
+	Tags:       []string{"maze", "racing", "time-trial"},
+	Author:     "@emmahsax",
+	Version:    "1.10.0",
+}
 
+// Actions of Delivery Dash on top of the shared ones, which players can rebind in their bindings file like any other
 const (
-	Up Direction = iota
-	Right
-	Down
-	Left
+	restartAction input.Action = "restart" // Play again on a new maze, once the run is over
+	retryAction   input.Action = "retry"   // Retry the same seed, once the run is over
+	useAction     input.Action = "use"     // Use the power-up the car is carrying
 )
 
+// DefaultBindings returns the shared bindings plus R to restart and Shift+R to retry (or Select on a gamepad), and E to
+// use a power-up
+func DefaultBindings() input.Bindings {
+	return input.DefaultBindings().With(input.Bindings{
+		restartAction: {
+			Keys:    input.Keys(ebiten.KeyR),
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightTop},
+		},
+		retryAction: {
+			Keys:    []input.Chord{{ebiten.KeyShift, ebiten.KeyR}},
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonCenterLeft},
+		},
+		useAction: {
+			Keys:    input.Keys(ebiten.KeyE),
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightLeft},
+		},
+	})
+}
+
+func init() {
+	registry.Register(registry.Game{
+		NewCommand: NewCommand,
+		Metadata:   Metadata,
+	})
+}
+
+// Game is the Ebitengine shell around a session: it reads the keyboard, steps the world once per frame, and draws
+// whatever state the world is in
 type Game struct {
-	car            *Car
-	maze           [][]bool // true for walls, false for paths
-	startX, startY int
-	endX, endY     int
-	lastMazeUpdate time.Time
-	gameOver       bool
-	win            bool
-	moveTimer      int                 // Counter for movement cooldown
-	startTime      time.Time           // When the player started moving
-	hasStarted     bool                // Whether the player has left the start position
-	finalTime      time.Duration       // Time taken to complete the maze
-	lastWallUpdate time.Time           // Last time walls were updated
-	lastKeyState   map[ebiten.Key]bool // Track last key press state
-	titleScreen    bool                // Whether to show the title screen
+	*session
+	carSprite      *ebiten.Image
+	trafficSprites map[sim.Behavior]*ebiten.Image // Sprites of the traffic, colored by how each vehicle drives
+	titleScreen    bool                           // Whether to show the title screen
+	menu           pauseMenu                      // Pause menu, opened with ESC or P
+	input          *input.Handler                 // Actions held this frame and the previous one, to detect new presses
+	cellSize       int                            // Size of each cell of the maze in pixels
+	carAnimation   carAnimation                   // Eases the car's sprite between cells
+	trafficMotion  []carAnimation                 // Eases each vehicle's sprite between cells, in the same order as the traffic
+	ghostMotion    carAnimation                   // Eases the ghost's sprite between cells
+	animated       *sim.World                     // World the animations are following, to start them over on a new one
 }
 
-type Car struct {
-	x, y         float64
-	direction    Direction
-	sprite       *ebiten.Image
-	cellX, cellY int     // Current cell position
-	rotation     float64 // Rotation in degrees
+// NewGame creates a game whose maze, shifting walls and moving customer are all derived from the seed
+func NewGame(opts Options) *Game {
+	return &Game{
+		session:   newSession(opts),
+		carSprite: newCarSprite(color.RGBA{255, 0, 0, 255}),
+		trafficSprites: map[sim.Behavior]*ebiten.Image{
+			sim.Patrol: newCarSprite(trafficBodyColors[sim.Patrol]),
+			sim.Wander: newCarSprite(trafficBodyColors[sim.Wander]),
+			sim.Chase:  newCarSprite(trafficBodyColors[sim.Chase]),
+		},
+		titleScreen: opts.Playback == nil, // Start with title screen, unless watching a replay
+		input:       input.NewHandler(opts.Bindings),
+		cellSize:    cellSizeOrDefault(opts.CellSize),
+	}
 }
 
-func NewGame() *Game {
+// trafficBodyColors are the colors of the traffic, by how each vehicle drives
+var trafficBodyColors = map[sim.Behavior]color.RGBA{
+	sim.Patrol: {230, 200, 0, 255},
+	sim.Wander: {0, 200, 200, 255},
+	sim.Chase:  {200, 0, 200, 255},
+}
+
+// newCarSprite draws a car with a body of the given color, facing down
+func newCarSprite(body color.RGBA) *ebiten.Image {
 	// Create a car sprite (a simple car shape for now)
 	carSprite := ebiten.NewImage(30, 20)
 	// Draw a simple car shape
 	carSprite.Fill(color.RGBA{0, 0, 0, 0}) // Clear the image
 	// Draw car body
-	vector.DrawFilledRect(carSprite, 0, 5, 30, 10, color.RGBA{255, 0, 0, 255}, false)
+	vector.DrawFilledRect(carSprite, 0, 5, 30, 10, body, false)
 	// Draw windows
 	vector.DrawFilledRect(carSprite, 5, 2, 8, 3, color.RGBA{200, 200, 255, 255}, false)
 	vector.DrawFilledRect(carSprite, 17, 2, 8, 3, color.RGBA{200, 200, 255, 255}, false)
@@ -125,375 +144,173 @@ func NewGame() *Game {
 	vector.DrawFilledRect(carSprite, 3, 15, 4, 5, color.RGBA{50, 50, 50, 255}, false)
 	vector.DrawFilledRect(carSprite, 23, 15, 4, 5, color.RGBA{50, 50, 50, 255}, false)
 
-	// Initialize maze
-	maze := make([][]bool, mazeHeight)
-	for i := range maze {
-		maze[i] = make([]bool, mazeWidth)
-	}
-
-	// Set start and end positions outside the maze
-	startX := mazeWidth / 2
-	startY := -1 // One cell above the maze
-	endX := mazeWidth / 2
-	endY := mazeHeight // One cell below the maze
-
-	// Create initial maze layout
-	generateMaze(maze, startX, 0, endX, mazeHeight-1) // Adjust path generation to connect to borders
-
-	// Create car at start position
-	car := &Car{
-		x:         float64((startX+1)*cellSize + cellSize/2), // +1 for border
-		y:         float64((startY+1)*cellSize + cellSize/2), // +1 for border
-		direction: Down,
-		sprite:    carSprite,
-		cellX:     startX,
-		cellY:     startY,
-		rotation:  0, // Start facing down (0 degrees)
-	}
-
-	// Initialize timers
-	now := time.Now()
-	return &Game{
-		car:            car,
-		maze:           maze,
-		startX:         startX,
-		startY:         startY,
-		endX:           endX,
-		endY:           endY,
-		lastMazeUpdate: now,
-		lastWallUpdate: now,
-		moveTimer:      0,
-		hasStarted:     false,
-		lastKeyState:   make(map[ebiten.Key]bool),
-		titleScreen:    true, // Start with title screen
-	}
+	return carSprite
 }
 
-func generateMaze(maze [][]bool, startX, startY, endX, endY int) {
-	// Initialize all cells as paths
-	for y := range maze {
-		for x := range maze[y] {
-			maze[y][x] = false
-		}
-	}
-
-	// Add random permanent walls
-	// Create a random pattern of walls in the middle section
-	centerX := mazeWidth / 2
-	centerY := mazeHeight / 2
-
-	// Add random walls in a 5x5 area around the center
-	for y := centerY - 2; y <= centerY+2; y++ {
-		for x := centerX - 2; x <= centerX+2; x++ {
-			if rand.Float32() < 0.6 { // 60% chance of wall
-				maze[y][x] = true
-			}
-		}
-	}
-
-	// Add some random diagonal walls
-	for i := 0; i < mazeHeight/2; i++ {
-		if rand.Float32() < 0.7 { // 70% chance of wall
-			maze[i][i] = true
-			maze[i][i+1] = true
-		}
-		if rand.Float32() < 0.7 { // 70% chance of wall
-			maze[i][mazeWidth-1-i] = true
-			maze[i][mazeWidth-2-i] = true
-		}
-	}
-
-	// Create a single path from start to end
-	currentX, currentY := startX, startY
-	maze[currentY][currentX] = false
-
-	// Create a path to the end
-	for currentX != endX || currentY != endY {
-		// Determine possible moves
-		var possibleMoves []Direction
-		if currentY > 0 && !maze[currentY-1][currentX] {
-			possibleMoves = append(possibleMoves, Up)
-		}
-		if currentX < mazeWidth-1 && !maze[currentY][currentX+1] {
-			possibleMoves = append(possibleMoves, Right)
-		}
-		if currentY < mazeHeight-1 && !maze[currentY+1][currentX] {
-			possibleMoves = append(possibleMoves, Down)
-		}
-		if currentX > 0 && !maze[currentY][currentX-1] {
-			possibleMoves = append(possibleMoves, Left)
-		}
-
-		// If no moves are possible, backtrack
-		if len(possibleMoves) == 0 {
-			break
-		}
-
-		// Choose a random move
-		move := possibleMoves[rand.Intn(len(possibleMoves))]
-		switch move {
-		case Up:
-			currentY--
-		case Right:
-			currentX++
-		case Down:
-			currentY++
-		case Left:
-			currentX--
-		}
-		maze[currentY][currentX] = false
+// cellSizeOrDefault returns the cell size to draw with, when one might not have been configured
+func cellSizeOrDefault(cellSize int) int {
+	if cellSize == 0 {
+		return defaultCellSize
 	}
+	return cellSize
+}
 
-	// Add some random walls (only 25% of the cells)
-	totalCells := mazeWidth * mazeHeight
-	wallsToAdd := totalCells / 4
-	for i := 0; i < wallsToAdd; i++ {
-		x := rand.Intn(mazeWidth)
-		y := rand.Intn(mazeHeight)
-		// Protect only the entrance and exit cells
-		if (x == startX && y == 0) || (x == endX && y == mazeHeight-1) {
-			continue
-		}
-		maze[y][x] = true
-	}
+// screenSize returns the size of the screen in pixels: the maze plus a border of one cell all the way around it
+func (g *Game) screenSize() (int, int) {
+	width, height := g.world.Size()
+	return g.cellSize * (width + 2), g.cellSize * (height + 2)
 }
 
 func (g *Game) Update() error {
-	// Check for escape key to exit (always check this first)
-	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
-		return ebiten.Termination
-	}
+	g.input.Update()
+	defer g.animate() // Whatever happened this frame
 
 	// Handle title screen
 	if g.titleScreen {
-		// Only accept Space or Enter to start
-		if ebiten.IsKeyPressed(ebiten.KeySpace) || ebiten.IsKeyPressed(ebiten.KeyEnter) {
+		// Only accept Confirm to start, Left and Right to pick the difficulty (or level, but nothing for the daily
+		// challenge), and Back to exit
+		switch {
+		case g.input.JustPressed(input.Back):
+			return ebiten.Termination
+		case g.input.JustPressed(input.Confirm):
 			g.titleScreen = false
-			return nil
+		case g.input.JustPressed(input.Left):
+			g.session = g.pick(-1)
+		case g.input.JustPressed(input.Right):
+			g.session = g.pick(1)
 		}
 		return nil
 	}
 
-	if g.gameOver || g.win {
+	// Handle the pause menu, without stepping the world so that everything stays frozen
+	if g.menu.open {
+		switch {
+		case g.input.JustPressed(input.Up):
+			return g.choose(g.menu.press(menuUp))
+		case g.input.JustPressed(input.Down):
+			return g.choose(g.menu.press(menuDown))
+		case g.input.JustPressed(input.Confirm):
+			return g.choose(g.menu.press(menuConfirm))
+		case g.input.JustPressed(input.Back), g.input.JustPressed(input.Pause):
+			return g.choose(g.menu.press(menuBack))
+		}
 		return nil
 	}
 
-	// Update movement cooldown
-	if g.moveTimer > 0 {
-		g.moveTimer--
-	}
-
-	// Check for maze updates
-	currentTime := time.Now()
-	timeSinceLastUpdate := currentTime.Sub(g.lastMazeUpdate)
-
-	if timeSinceLastUpdate >= time.Duration(float64(mazeUpdateInterval)*float64(time.Second)) {
-		// Try to move the end position multiple times
-		for i := 0; i < 2; i++ { // Try to move up to 2 times per update
-			oldEndX := g.endX
-			// Try to jump to a random position at the bottom
-			newEndX := rand.Intn(mazeWidth)
-			g.endX = newEndX
-			// If the new position would trap the player, revert the change
-			if g.wouldTrapPlayer(g.endX, mazeHeight-1) {
-				g.endX = oldEndX
-			}
-		}
-
-		g.lastMazeUpdate = currentTime
-	}
-
-	// Check for wall updates (separate from end position updates)
-	timeSinceWallUpdate := currentTime.Sub(g.lastWallUpdate)
-	if timeSinceWallUpdate >= time.Duration(float64(wallUpdateInterval)*float64(time.Second)) {
-		// Update only 25% of the walls, ensuring player is never trapped
-		wallsChanged := 0
-		for y := range g.maze {
-			for x := range g.maze[y] {
-				// Protect only the entrance, exit, and permanent walls
-				centerX := mazeWidth / 2
-				centerY := mazeHeight / 2
-				isProtected := (x == g.startX && y == 0) || // Start position
-					(x == g.endX && y == mazeHeight-1) || // End position
-					(y < mazeHeight/2 && (x == y || x == y+1)) || // First diagonal
-					(y < mazeHeight/2 && (x == mazeWidth-1-y || x == mazeWidth-2-y)) || // Second diagonal
-					(x == centerX && y >= centerY-1 && y <= centerY+1) || // Center vertical
-					(y == centerY && x >= centerX-1 && x <= centerX+1) // Center horizontal
-
-				if !isProtected && rand.Float32() < wallChangeChance {
-					// Temporarily store the current state
-					oldState := g.maze[y][x]
-					// Try the change
-					g.maze[y][x] = !g.maze[y][x]
-					// If it would trap the player, revert the change
-					if g.wouldTrapPlayer(x, y) {
-						g.maze[y][x] = oldState
-					} else {
-						wallsChanged++
-					}
-				}
-			}
-		}
-		g.lastWallUpdate = currentTime
+	if g.input.JustPressed(input.Pause) {
+		g.menu.show()
+		return nil
 	}
 
-	// Handle car movement with improved key detection
-	if g.moveTimer == 0 {
-		// Check for key press transitions (key just pressed)
-		if (ebiten.IsKeyPressed(ebiten.KeyUp) || ebiten.IsKeyPressed(ebiten.KeyW)) && !g.lastKeyState[ebiten.KeyUp] && !g.lastKeyState[ebiten.KeyW] {
-			g.moveCar(Up)
-			g.moveTimer = moveCooldown
-		}
-		if (ebiten.IsKeyPressed(ebiten.KeyRight) || ebiten.IsKeyPressed(ebiten.KeyD)) && !g.lastKeyState[ebiten.KeyRight] && !g.lastKeyState[ebiten.KeyD] {
-			g.moveCar(Right)
-			g.moveTimer = moveCooldown
-		}
-		if (ebiten.IsKeyPressed(ebiten.KeyDown) || ebiten.IsKeyPressed(ebiten.KeyS)) && !g.lastKeyState[ebiten.KeyDown] && !g.lastKeyState[ebiten.KeyS] {
-			g.moveCar(Down)
-			g.moveTimer = moveCooldown
-		}
-		if (ebiten.IsKeyPressed(ebiten.KeyLeft) || ebiten.IsKeyPressed(ebiten.KeyA)) && !g.lastKeyState[ebiten.KeyLeft] && !g.lastKeyState[ebiten.KeyA] {
-			g.moveCar(Left)
-			g.moveTimer = moveCooldown
+	// Once the run is over, restart plays again on a new maze (or the next level of the campaign) and retry plays the
+	// same seed again (retry is checked first, since holding Shift+R also holds R)
+	if g.finished() {
+		switch {
+		case g.input.JustPressed(retryAction):
+			g.session = g.restart(true)
+			return nil
+		case g.input.JustPressed(restartAction):
+			g.session = g.restart(false)
+			return nil
 		}
 	}
 
-	// Update last key states
-	g.lastKeyState[ebiten.KeyUp] = ebiten.IsKeyPressed(ebiten.KeyUp)
-	g.lastKeyState[ebiten.KeyW] = ebiten.IsKeyPressed(ebiten.KeyW)
-	g.lastKeyState[ebiten.KeyRight] = ebiten.IsKeyPressed(ebiten.KeyRight)
-	g.lastKeyState[ebiten.KeyD] = ebiten.IsKeyPressed(ebiten.KeyD)
-	g.lastKeyState[ebiten.KeyDown] = ebiten.IsKeyPressed(ebiten.KeyDown)
-	g.lastKeyState[ebiten.KeyS] = ebiten.IsKeyPressed(ebiten.KeyS)
-	g.lastKeyState[ebiten.KeyLeft] = ebiten.IsKeyPressed(ebiten.KeyLeft)
-	g.lastKeyState[ebiten.KeyA] = ebiten.IsKeyPressed(ebiten.KeyA)
-
-	// Check for win condition
-	if g.car.cellY == mazeHeight && g.car.cellX == g.endX {
-		g.win = true
-		g.finalTime = time.Since(g.startTime)
-	}
+	g.step(sim.Input{
+		Up:    g.input.Pressed(input.Up),
+		Right: g.input.Pressed(input.Right),
+		Down:  g.input.Pressed(input.Down),
+		Left:  g.input.Pressed(input.Left),
+		Use:   g.input.Pressed(useAction),
+	})
 
 	return nil
 }
 
-func (g *Game) wouldTrapPlayer(x, y int) bool {
-	// Create a temporary copy of the maze
-	tempMaze := make([][]bool, len(g.maze))
-	for i := range g.maze {
-		tempMaze[i] = make([]bool, len(g.maze[i]))
-		copy(tempMaze[i], g.maze[i])
+// choose acts on what the player picked from the pause menu
+func (g *Game) choose(choice menuChoice) error {
+	switch choice {
+	case choiceRestartSameSeed:
+		g.session = g.restart(true)
+	case choiceRestartNewMaze:
+		g.session = g.restart(false)
+	case choiceQuitToTitle:
+		g.session = g.restart(false)
+		g.titleScreen = true
+	case choiceExit:
+		return ebiten.Termination
 	}
 
-	// Check if there's a path from player to end
-	return !g.hasPathToEnd(tempMaze, g.car.cellX, g.car.cellY)
+	return nil
 }
 
-func (g *Game) hasPathToEnd(maze [][]bool, startX, startY int) bool {
-	visited := make([][]bool, len(maze))
-	for i := range maze {
-		visited[i] = make([]bool, len(maze[i]))
+// animate catches the animations of the car and the traffic up with the world, easing each move over the cooldown the
+// car or vehicle has to wait before its next one
+func (g *Game) animate() {
+	if g.animated != g.world {
+		g.animated = g.world
+		g.carAnimation = carAnimation{}
+		g.ghostMotion = carAnimation{}
+		g.trafficMotion = make([]carAnimation, len(g.world.Traffic()))
 	}
 
-	var dfs func(x, y int) bool
-	dfs = func(x, y int) bool {
-		if x < 0 || x >= mazeWidth || y < 0 || y >= mazeHeight || visited[y][x] || maze[y][x] {
-			return false
-		}
-		if x == g.endX && y == mazeHeight-1 {
-			return true
-		}
-		visited[y][x] = true
-		return dfs(x+1, y) || dfs(x-1, y) || dfs(x, y+1) || dfs(x, y-1)
+	tick := g.world.Tick()
+	cooldown := g.world.Config().Difficulty.MoveCooldown
+	carCooldown := cooldown
+	if g.world.Turbo() > 0 {
+		carCooldown = max(carCooldown/2, 1)
 	}
 
-	return dfs(startX, startY)
+	g.carAnimation.follow(g.world.Car(), tick, carCooldown)
+	if bumped := g.world.Bumped(); bumped > 0 && bumped != g.carAnimation.bumped {
+		g.carAnimation.bump(bumped, g.world.Car().Direction)
+	}
+	for i, vehicle := range g.world.Traffic() {
+		g.trafficMotion[i].follow(vehicle.Car, tick, cooldown*3/2) // Traffic moves half as slowly again as the car
+	}
+	if ghost, ok := g.ghostCar(); ok {
+		g.ghostMotion.follow(ghost, tick, cooldown)
+	}
 }
 
-func (g *Game) moveCar(dir Direction) {
-	// Calculate new cell position
-	newCellX, newCellY := g.car.cellX, g.car.cellY
-
-	// Calculate the target position based on direction
+// carRotation returns the rotation of the car sprite in degrees, where the sprite faces down at 0 degrees
+func carRotation(dir sim.Direction) float64 {
 	switch dir {
-	case Up:
-		newCellY--
-		g.car.rotation = 180 // Face up (180 degrees from down)
-	case Right:
-		newCellX++
-		g.car.rotation = 270 // Face right (270 degrees from down)
-	case Down:
-		newCellY++
-		g.car.rotation = 0 // Face down (0 degrees)
-	case Left:
-		newCellX--
-		g.car.rotation = 90 // Face left (90 degrees from down)
-	}
-
-	// Special case for start position (above maze)
-	if g.car.cellY == -1 {
-		if newCellY == 0 && !g.maze[0][newCellX] {
-			// Allow movement into the maze
-			g.car.cellX = newCellX
-			g.car.cellY = newCellY
-			g.car.direction = dir
-			g.car.x = float64((newCellX+1)*cellSize + cellSize/2)
-			g.car.y = float64((newCellY+1)*cellSize + cellSize/2)
-			// Start the timer when leaving the start position
-			if !g.hasStarted {
-				g.hasStarted = true
-				g.startTime = time.Now()
-			}
-		}
-		return
-	}
-
-	// Special case for end position (below maze)
-	if g.car.cellY == mazeHeight-1 && dir == Down && newCellX == g.endX {
-		// Allow movement to the end position
-		g.car.cellX = newCellX
-		g.car.cellY = newCellY
-		g.car.direction = dir
-		g.car.x = float64((newCellX+1)*cellSize + cellSize/2)
-		g.car.y = float64((newCellY+1)*cellSize + cellSize/2)
-		return
+	case sim.Up:
+		return 180 // Face up (180 degrees from down)
+	case sim.Right:
+		return 270 // Face right (270 degrees from down)
+	case sim.Left:
+		return 90 // Face left (90 degrees from down)
+	default:
+		return 0 // Face down (0 degrees)
 	}
+}
 
-	// Normal maze movement
-	if newCellX >= 0 && newCellX < mazeWidth &&
-		newCellY >= 0 && newCellY < mazeHeight &&
-		!g.maze[newCellY][newCellX] {
-		// Update car position
-		g.car.cellX = newCellX
-		g.car.cellY = newCellY
-		g.car.direction = dir
-		// Center the car in the new cell
-		g.car.x = float64((newCellX+1)*cellSize + cellSize/2)
-		g.car.y = float64((newCellY+1)*cellSize + cellSize/2)
-	}
+// cellCenter returns the screen position of the middle of a maze cell
+func (g *Game) cellCenter(cellX, cellY int) (float64, float64) {
+	cellSize := g.cellSize
+	return float64((cellX+1)*cellSize + cellSize/2), float64((cellY+1)*cellSize + cellSize/2) // +1 for border
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	cellSize := g.cellSize
+	screenWidth, screenHeight := g.screenSize()
+	mazeWidth, mazeHeight := g.world.Size()
+
 	// Draw background
 	screen.Fill(color.RGBA{50, 50, 50, 255})
 
 	if g.titleScreen {
 		// Draw title screen
-		title := "DELIVERY DASH"
-		scenario := "You are a delivery driver in a chaotic, ever-changing city.\n" +
-			"The walls are shifting beneath your wheels and the customer keeps moving!\n" +
-			"Navigate through the maze to deliver your package as fast\n" +
-			"as possible, before the walls trap you!\n\n" +
-			"Use arrow keys or WASD to move.\n\n" +
-			"Press SPACE or ENTER to start, ESC to exit"
+		title := strings.ToUpper(Metadata.Title)
+		lines := g.titleScreenLines()
 
 		// Draw title
-		ebitenutil.DebugPrintAt(screen, title, screenWidth/2-100, screenHeight/2-100)
+		ebitenutil.DebugPrintAt(screen, title, max(screenWidth/2-100, 0), max(screenHeight/2-100, 0))
 
-		// Draw scenario text (split into lines)
-		lines := strings.Split(scenario, "\n")
+		// Draw scenario text
 		for i, line := range lines {
-			ebitenutil.DebugPrintAt(screen, line, screenWidth/2-200, screenHeight/2-50+i*20)
+			ebitenutil.DebugPrintAt(screen, line, max(screenWidth/2-200, 0), max(screenHeight/2-100, 0)+50+i*20)
 		}
 
 		return
@@ -509,10 +326,20 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		false,
 	)
 
-	// Draw maze walls as thin lines
-	for y := range g.maze {
-		for x := range g.maze[y] {
-			if g.maze[y][x] {
+	// Draw maze walls as thin lines, turning icy while they're frozen. A level's permanent walls are drawn brighter, so
+	// they can be told apart from the ones that shift.
+	shiftingColor := color.RGBA{100, 100, 100, 255}
+	if g.world.Frozen() > 0 {
+		shiftingColor = color.RGBA{120, 170, 220, 255}
+	}
+	level := g.world.Config().Level != nil
+	for y := 0; y < mazeHeight; y++ {
+		for x := 0; x < mazeWidth; x++ {
+			wallColor := shiftingColor
+			if level && g.world.Permanent(x, y) {
+				wallColor = color.RGBA{200, 200, 200, 255}
+			}
+			if g.world.Wall(x, y) {
 				// Draw a cross of lines for wall cells
 				// Vertical line in the middle of the cell
 				vector.DrawFilledRect(screen,
@@ -520,7 +347,7 @@ func (g *Game) Draw(screen *ebiten.Image) {
 					float32((y+1)*cellSize),            // +1 for border
 					float32(wallThickness),
 					float32(cellSize),
-					color.RGBA{100, 100, 100, 255},
+					wallColor,
 					false,
 				)
 				// Horizontal line in the middle of the cell
@@ -529,7 +356,7 @@ func (g *Game) Draw(screen *ebiten.Image) {
 					float32((y+1)*cellSize+cellSize/2), // +1 for border
 					float32(cellSize),
 					float32(wallThickness),
-					color.RGBA{100, 100, 100, 255},
+					wallColor,
 					false,
 				)
 			} else {
@@ -547,64 +374,557 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	}
 
 	// Draw start and end positions
+	startX, startY := g.world.Start()
+	endX, endY := g.world.End()
 	vector.DrawFilledRect(screen,
-		float32((g.startX+1)*cellSize), // +1 for border
-		float32((g.startY+1)*cellSize), // +1 for border
+		float32((startX+1)*cellSize), // +1 for border
+		float32((startY+1)*cellSize), // +1 for border
 		float32(cellSize),
 		float32(cellSize),
 		color.RGBA{0, 255, 0, 255},
 		false,
 	)
-	vector.DrawFilledRect(screen,
-		float32((g.endX+1)*cellSize), // +1 for border
-		float32((g.endY+1)*cellSize), // +1 for border
-		float32(cellSize),
-		float32(cellSize),
-		color.RGBA{0, 0, 255, 255},
-		false,
-	)
+	if g.world.Shift() {
+		g.drawPackages(screen)
+	} else {
+		vector.DrawFilledRect(screen,
+			float32((endX+1)*cellSize), // +1 for border
+			float32((endY+1)*cellSize), // +1 for border
+			float32(cellSize),
+			float32(cellSize),
+			color.RGBA{0, 0, 255, 255},
+			false,
+		)
+	}
+
+	// Draw where the customer might go next while Radar is in use
+	for _, x := range g.world.Radar() {
+		vector.StrokeRect(screen,
+			float32((x+1)*cellSize+2), // +1 for border
+			float32((endY+1)*cellSize+2),
+			float32(cellSize-4),
+			float32(cellSize-4),
+			2,
+			color.RGBA{100, 150, 255, 255},
+			false,
+		)
+	}
+
+	// Draw the power-ups lying around, as a colored square with the power-up's initial
+	for _, pickup := range g.world.PowerUps() {
+		x, y := g.cellCenter(pickup.CellX, pickup.CellY)
+		vector.DrawFilledRect(screen, float32(x)-float32(cellSize)/4, float32(y)-float32(cellSize)/4, float32(cellSize)/2, float32(cellSize)/2, powerUpColors[pickup.PowerUp], false)
+		ebitenutil.DebugPrintAt(screen, powerUpName(pickup.PowerUp)[:1], int(x)-3, int(y)-8) // The debug font is 6x16 pixels
+	}
+
+	// Draw the traffic, then the ghost of the player's best run on this seed, and then the car on top
+	for i, vehicle := range g.world.Traffic() {
+		var motion *carAnimation
+		if i < len(g.trafficMotion) {
+			motion = &g.trafficMotion[i]
+		}
+		g.drawCar(screen, g.trafficSprites[vehicle.Behavior], vehicle.Car, motion, 1)
+	}
+	if ghost, ok := g.ghostCar(); ok {
+		g.drawCar(screen, g.carSprite, ghost, &g.ghostMotion, ghostAlpha)
+	}
+	g.drawCar(screen, g.carSprite, g.world.Car(), &g.carAnimation, 1)
+
+	// Draw the time, and the game over or win message
+	ebitenutil.DebugPrint(screen, g.statusLine(g.menu.settings))
+	g.drawGauges(screen)
+	g.drawInventory(screen)
+	if g.finished() {
+		g.drawResults(screen)
+	}
+
+	if g.menu.open {
+		g.drawMenu(screen)
+	}
+}
+
+// drawCar draws a car's sprite where its animation has got to, turned the way it's facing so far, or right in its cell
+// when the animation hasn't caught up with the world yet, as solid as alpha (from 0 to 1)
+func (g *Game) drawCar(screen *ebiten.Image, sprite *ebiten.Image, car sim.Car, motion *carAnimation, alpha float32) {
+	x, y := float64(car.CellX), float64(car.CellY)
+	rotation := carRotation(car.Direction)
+	if motion != nil && motion.started && g.animated == g.world {
+		x, y = motion.position(g.world.Tick())
+		rotation = motion.rotation(g.world.Tick())
+	}
 
-	// Draw car with rotation
 	op := &ebiten.DrawImageOptions{}
 	// Set the rotation center to the middle of the car
-	op.GeoM.Translate(-15, -10)                             // Move to center
-	op.GeoM.Rotate(float64(g.car.rotation) * math.Pi / 180) // Rotate
-	op.GeoM.Translate(g.car.x, g.car.y)                     // Move to position
-	screen.DrawImage(g.car.sprite, op)
-
-	// Draw game over or win message
-	if g.gameOver {
-		ebitenutil.DebugPrint(screen, "Game Over - Press ESC to exit")
-	} else if g.win {
-		// Show final time
-		ebitenutil.DebugPrint(screen, fmt.Sprintf("Total Time: %.2f seconds - Press ESC to exit", g.finalTime.Seconds()))
-	} else if g.hasStarted {
-		// Show current time while playing
-		elapsed := time.Since(g.startTime)
-		ebitenutil.DebugPrint(screen, fmt.Sprintf("Time: %.2f - Press ESC to exit", elapsed.Seconds()))
+	op.GeoM.Translate(-15, -10)              // Move to center
+	op.GeoM.Rotate(rotation * math.Pi / 180) // Rotate
+	op.GeoM.Scale(float64(g.cellSize)/spriteCellSize, float64(g.cellSize)/spriteCellSize)
+	op.GeoM.Translate(float64(g.cellSize)*(x+1.5), float64(g.cellSize)*(y+1.5)) // Move to position, +1 for border
+	op.ColorScale.ScaleAlpha(alpha)
+	screen.DrawImage(sprite, op)
+}
+
+// drawPackages draws a shift's packages waiting in the maze as small boxes, and each customer still waiting for one as
+// a square in the border, both in the package's color and numbered so they can be matched up
+func (g *Game) drawPackages(screen *ebiten.Image) {
+	cellSize := g.cellSize
+	mazeWidth, mazeHeight := g.world.Size()
+
+	for i, p := range g.world.Packages() {
+		fill := packageColors[i%len(packageColors)]
+		label := fmt.Sprintf("%d", i+1)
+
+		if p.State == sim.Waiting {
+			x, y := g.cellCenter(p.CellX, p.CellY)
+			vector.DrawFilledRect(screen, float32(x)-float32(cellSize)/3, float32(y)-float32(cellSize)/3, float32(cellSize)*2/3, float32(cellSize)*2/3, color.RGBA{150, 100, 50, 255}, false)
+			vector.StrokeRect(screen, float32(x)-float32(cellSize)/3, float32(y)-float32(cellSize)/3, float32(cellSize)*2/3, float32(cellSize)*2/3, 2, fill, false)
+			ebitenutil.DebugPrintAt(screen, label, int(x)-len(label)*3, int(y)-8) // The debug font is 6x16 pixels
+		}
+
+		if p.State != sim.Delivered {
+			cellX, cellY := p.Customer(mazeWidth, mazeHeight)
+			vector.DrawFilledRect(screen, float32((cellX+1)*cellSize), float32((cellY+1)*cellSize), float32(cellSize), float32(cellSize), fill, false) // +1 for border
+			x, y := g.cellCenter(cellX, cellY)
+			ebitenutil.DebugPrintAt(screen, label, int(x)-len(label)*3, int(y)-8)
+		}
 	}
 }
 
+// drawGauges draws the fuel and deadline bars in the top right corner, above the maze, for the difficulties that have
+// them
+func (g *Game) drawGauges(screen *ebiten.Image) {
+	screenWidth, _ := g.screenSize()
+	fuelLabel, deadlineLabel := g.hudLabels()
+	x := screenWidth - gaugeWidth - 10
+
+	y := 2
+	for _, gauge := range []struct {
+		label string
+		read  func() (float64, bool)
+		fill  color.RGBA
+	}{
+		{fuelLabel, g.fuelGauge, color.RGBA{230, 140, 0, 255}},
+		{deadlineLabel, g.deadlineGauge, color.RGBA{230, 230, 0, 255}},
+	} {
+		level, ok := gauge.read()
+		if !ok {
+			continue
+		}
+		if level < 0.25 {
+			gauge.fill = color.RGBA{230, 30, 30, 255} // Running low
+		}
+
+		ebitenutil.DebugPrintAt(screen, gauge.label, x-len(gauge.label)*6-8, y) // The debug font is 6 pixels wide
+		vector.DrawFilledRect(screen, float32(x), float32(y+4), gaugeWidth, 8, color.RGBA{20, 20, 20, 255}, false)
+		vector.DrawFilledRect(screen, float32(x), float32(y+4), float32(gaugeWidth*level), 8, gauge.fill, false)
+		y += 16
+	}
+}
+
+// drawInventory draws the power-up the car is carrying in a slot in the bottom left corner, below the maze, along
+// with the power-ups in use
+func (g *Game) drawInventory(screen *ebiten.Image) {
+	_, screenHeight := g.screenSize()
+	y := screenHeight - g.cellSize/2 - 8
+
+	if carrying := g.world.Carrying(); carrying != sim.NoPowerUp {
+		vector.DrawFilledRect(screen, 4, float32(y), 16, 16, powerUpColors[carrying], false)
+	}
+	vector.StrokeRect(screen, 4, float32(y), 16, 16, 1, color.RGBA{200, 200, 200, 255}, false)
+	ebitenutil.DebugPrintAt(screen, g.inventoryLine(), 26, y)
+}
+
+// drawResults draws the results and the top scores over the maze, highlighting the run that was just finished
+func (g *Game) drawResults(screen *ebiten.Image) {
+	// Keep the results on the screen when the maze is smaller than they are
+	screenWidth, screenHeight := g.screenSize()
+	left := max(screenWidth/2-200, 20)
+	top := max(screenHeight/2-240, 20)
+
+	// Dim the maze behind the results
+	results := g.resultLines()
+	vector.DrawFilledRect(screen, float32(left-20), float32(top-20), 440, float32(90+20*(len(results)+scores.MaxEntries)), color.RGBA{0, 0, 0, 200}, false)
+
+	for i, line := range results {
+		ebitenutil.DebugPrintAt(screen, line, left, top+i*20)
+	}
+
+	if !g.scoreSaved {
+		return
+	}
+	top += len(results)*20 + 20
+
+	ebitenutil.DebugPrintAt(screen, g.leaderboardHeadline(), left, top)
+	if g.scoreErr != nil {
+		return
+	}
+
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("TOP %d", scores.MaxEntries), left, top+30)
+	for i, entry := range g.topScores {
+		y := top + 50 + i*20
+
+		// Highlight the run that was just finished
+		if i+1 == g.scoreRank {
+			vector.DrawFilledRect(screen, float32(left-5), float32(y), 370, 18, color.RGBA{180, 140, 0, 255}, false)
+		}
+
+		ebitenutil.DebugPrintAt(screen, leaderboardRow(i+1, entry), left, y)
+	}
+}
+
+// drawMenu draws the pause menu over the maze, hiding the maze so it can't be studied while the clock is stopped
+func (g *Game) drawMenu(screen *ebiten.Image) {
+	screenWidth, screenHeight := g.screenSize()
+	vector.DrawFilledRect(screen, 0, 0, float32(screenWidth), float32(screenHeight), color.RGBA{0, 0, 0, 230}, false)
+
+	for i, line := range g.menu.lines() {
+		ebitenutil.DebugPrintAt(screen, line, max(screenWidth/2-150, 0), max(screenHeight/2-100, 0)+i*20)
+	}
+}
+
+// titleScreenLines builds the title screen text from the game's metadata and the difficulty (or level) picked
+func (s *session) titleScreenLines() []string {
+	var lines []string
+
+	// Wrap the description so it fits on the screen
+	line := ""
+	for _, word := range strings.Fields(Metadata.Description) {
+		if line != "" && len(line)+1+len(word) > titleScreenLineLength {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	lines = append(lines, line, "")
+
+	for _, control := range Metadata.Controls {
+		lines = append(lines, fmt.Sprintf("%s: %s", control.Keys, control.Action))
+	}
+
+	lines = append(lines, "")
+	if level := s.world.Config().Level; level != nil {
+		lines = append(lines, s.levelTitle(level)...)
+	}
+	if s.opts.Daily != "" {
+		lines = append(lines, s.dailyLines()...)
+		return append(lines, "Difficulty: "+difficultyTitle(s.world.Config().Difficulty.Name())) // The same for everyone
+	}
+	if s.campaignLevel() >= 0 {
+		return append(lines, "Difficulty: "+difficultyTitle(s.world.Config().Difficulty.Name())) // Each level has its own
+	}
+	return append(lines, fmt.Sprintf("Difficulty: < %s >", difficultyTitle(s.world.Config().Difficulty.Name())))
+}
+
+// levelTitle returns the lines about the level being played on the title screen: its name (which can be changed with
+// Left and Right in the campaign), its description, and the best it's been played
+func (s *session) levelTitle(level *sim.Level) []string {
+	name := "Level: " + level.Name
+	if current := s.campaignLevel(); current >= 0 {
+		name = fmt.Sprintf("Level: < %d/%d %s >", current+1, len(s.opts.Campaign), level.Name)
+	}
+	lines := []string{name}
+	if level.Description != "" {
+		lines = append(lines, level.Description)
+	}
+
+	par := "none"
+	if level.Par > 0 {
+		par = fmt.Sprintf("%.2fs", level.Par)
+	}
+	if s.progress != nil {
+		if record, played := s.progress.Levels[level.Name]; played {
+			par += "   Best: " + scores.FormatTime(record.Best)
+			if record.BeatPar {
+				par += " (par beaten)"
+			}
+		}
+	}
+	return append(lines, "Par: "+par)
+}
+
+// Layout returns the size of the maze on screen, which Ebitengine scales to fit the window
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
+	return g.screenSize()
+}
+
+// windowSize returns the size to open the window at: the screen at its full size, or scaled down to fit on the
+// monitor when it's too big for it
+func windowSize(screenWidth, screenHeight int) (int, int) {
+	scale := 1.0
+	if monitor := ebiten.Monitor(); monitor != nil {
+		if monitorWidth, monitorHeight := monitor.Size(); monitorWidth > 0 && monitorHeight > 0 {
+			scale = min(scale, windowMargin*float64(monitorWidth)/float64(screenWidth), windowMargin*float64(monitorHeight)/float64(screenHeight))
+		}
+	}
+
+	return int(float64(screenWidth) * scale), int(float64(screenHeight) * scale)
+}
+
+// durationToTicks converts a duration from the command-line into ticks of the world, rounding up so that short
+// durations still count
+func durationToTicks(d time.Duration) int {
+	tick := time.Second / sim.TicksPerSecond
+	return int((d + tick - 1) / tick)
+}
+
+// ticksToDuration converts ticks of the world into a duration for the command-line, dropping parts of a millisecond
+// (which durationToTicks rounds back up to the same ticks)
+func ticksToDuration(ticks int) time.Duration {
+	return (time.Duration(ticks) * time.Second / sim.TicksPerSecond).Truncate(time.Millisecond)
+}
+
+// difficultyChoices returns the names of every difficulty that can be picked with --difficulty
+func difficultyChoices() []string {
+	return append(sim.Difficulties(), sim.CustomDifficulty)
 }
 
 func NewCommand() *cobra.Command {
+	var seed int64
+	var recordPath, replayPath string
+	var player string
+	var useTerminal, ascii bool
+	var buffer int
+	var generator, difficulty string
+	var wallChance, deadline, fuel float64
+	var traffic, powerUps int
+	var crashesEnd bool
+	var wallInterval, customerInterval, moveCooldown time.Duration
+	var repeatDelay, repeatInterval time.Duration
+	var width, height, cellSize int
+	var packages, capacity int
+	var levelPath string
+	var campaign, daily bool
+
+	normal := sim.DefaultConfig().Difficulty
+
 	cmd := &cobra.Command{
-		Use:     "delivery-dash",
+		Use:     gameName,
 		Aliases: []string{"dd"},
 		Short:   "Escape the chaotic, ever-changing maze to deliver your package to the customer! (alias: dd)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ebiten.SetWindowSize(screenWidth, screenHeight)
-			ebiten.SetWindowTitle("Delivery Dash")
+			if recordPath != "" && replayPath != "" {
+				return fmt.Errorf("--record and --replay can't be used together")
+			}
+			if daily {
+				// The daily challenge is the same city for everyone, so nothing about it can be changed
+				for _, name := range []string{"seed", "difficulty", "wall-chance", "wall-interval", "customer-interval", "move-cooldown", "deadline", "fuel", "traffic", "crashes-end", "power-ups", "width", "height", "generator", "packages", "capacity", "level", "campaign", "replay"} {
+					if cmd.Flags().Changed(name) {
+						return fmt.Errorf("--%s can't be used with --daily, which is the same for everyone", name)
+					}
+				}
+			}
+			if _, ok := sim.Generator(generator); !ok {
+				return fmt.Errorf("unknown maze generator %q (choose from %s)", generator, strings.Join(sim.Generators(), ", "))
+			}
+			if width < sim.MinMazeSize || width > sim.MaxMazeSize || height < sim.MinMazeSize || height > sim.MaxMazeSize {
+				return fmt.Errorf("--width and --height must be between %d and %d cells", sim.MinMazeSize, sim.MaxMazeSize)
+			}
+			if cellSize < minCellSize || cellSize > maxCellSize {
+				return fmt.Errorf("--cell-size must be between %d and %d pixels", minCellSize, maxCellSize)
+			}
+			if packages < 0 || packages > sim.MaxPackages {
+				return fmt.Errorf("--packages must be between 0 and %d", sim.MaxPackages)
+			}
+			if capacity < 1 {
+				return fmt.Errorf("--capacity must be at least 1")
+			}
+
+			// Tuning any part of the difficulty makes it a custom one, where the rest is the same as the default preset
+			for _, name := range []string{"wall-chance", "wall-interval", "customer-interval", "move-cooldown", "deadline", "fuel", "traffic", "crashes-end", "power-ups"} {
+				if !cmd.Flags().Changed(name) {
+					continue
+				}
+				if cmd.Flags().Changed("difficulty") && difficulty != sim.CustomDifficulty {
+					return fmt.Errorf("--%s can only be used with --difficulty %s", name, sim.CustomDifficulty)
+				}
+				difficulty = sim.CustomDifficulty
+			}
+			if wallChance < 0 || wallChance > 1 {
+				return fmt.Errorf("--wall-chance must be between 0 and 1")
+			}
+			if wallInterval <= 0 || customerInterval <= 0 || moveCooldown <= 0 {
+				return fmt.Errorf("--wall-interval, --customer-interval and --move-cooldown must be more than 0")
+			}
+			if (deadline != 0 && deadline < 1) || (fuel != 0 && fuel < 1) {
+				return fmt.Errorf("--deadline and --fuel must be at least 1 (or 0 for none)")
+			}
+			if traffic < 0 || powerUps < 0 {
+				return fmt.Errorf("--traffic and --power-ups can't be negative")
+			}
+			custom := sim.Difficulty{
+				WallChance:       wallChance,
+				WallInterval:     durationToTicks(wallInterval),
+				CustomerInterval: durationToTicks(customerInterval),
+				MoveCooldown:     durationToTicks(moveCooldown),
+				Deadline:         deadline,
+				Fuel:             fuel,
+				Traffic:          traffic,
+				CrashesEnd:       crashesEnd,
+				PowerUps:         powerUps,
+			}
+
+			picked, ok := sim.DifficultyPreset(difficulty)
+			switch {
+			case difficulty == sim.CustomDifficulty:
+				picked = custom
+			case !ok:
+				return fmt.Errorf("unknown difficulty %q (choose from %s)", difficulty, strings.Join(difficultyChoices(), ", "))
+			}
 
-			if err := ebiten.RunGame(NewGame()); err != nil {
+			// A level has its own map, and is played on its own difficulty unless the player picks another (which they
+			// can't in the campaign, where every level is ranked as it was made)
+			var level *sim.Level
+			var levels []*sim.Level
+			switch {
+			case campaign && levelPath != "":
+				return fmt.Errorf("--campaign and --level can't be used together")
+			case campaign && replayPath != "":
+				return fmt.Errorf("--campaign and --replay can't be used together")
+			case campaign:
+				var err error
+				if levels, err = campaignLevels(); err != nil {
+					return err
+				}
+				progress, err := loadProgress()
+				if err != nil {
+					return err
+				}
+				level = levels[progress.next(levels)]
+			case levelPath != "":
+				var err error
+				if level, err = sim.LoadLevel(levelPath); err != nil {
+					return err
+				}
+			}
+			if level != nil {
+				for _, name := range []string{"width", "height", "generator"} {
+					if cmd.Flags().Changed(name) {
+						return fmt.Errorf("--%s can't be used with a level, which has its own map", name)
+					}
+				}
+				chosen := cmd.Flags().Changed("difficulty") || difficulty == sim.CustomDifficulty
+				switch {
+				case chosen && campaign:
+					return fmt.Errorf("--difficulty and its tuning can't be used with --campaign, where each level has its own")
+				case !chosen:
+					picked = levelDifficulty(level)
+				}
+			}
+
+			// Pick a random seed unless the player asked for a specific city, or the day's
+			if !cmd.Flags().Changed("seed") {
+				seed = time.Now().UnixNano()
+			}
+			date := ""
+			if daily {
+				date = dailyDate(time.Now())
+				seed, picked = dailySeed(date), dailyDifficulty(date)
+			}
+
+			bindings, err := input.Load(DefaultBindings())
+			if err != nil {
 				return err
 			}
 
+			opts := Options{
+				Seed:     seed,
+				Record:   recordPath != "",
+				Player:   player,
+				Bindings: bindings,
+				CellSize: cellSize,
+				Custom:   custom,
+				Config: sim.Config{
+					Width:          width,
+					Height:         height,
+					Difficulty:     picked,
+					InputBuffer:    buffer,
+					RepeatDelay:    durationToTicks(repeatDelay),
+					RepeatInterval: durationToTicks(repeatInterval),
+					Generator:      generator,
+					Packages:       packages,
+					Capacity:       capacity,
+					Level:          level,
+				},
+				Campaign: levels,
+				Daily:    date,
+			}
+
+			if replayPath != "" {
+				replay, err := sim.LoadReplay(replayPath)
+				if err != nil {
+					return err
+				}
+				if replay.GameVersion != Metadata.Version {
+					return fmt.Errorf("replay was recorded with Delivery Dash %s, but this is %s", replay.GameVersion, Metadata.Version)
+				}
+				opts.Playback = replay
+			}
+
+			// Play in the terminal when asked to, or when there's no display to open a window on
+			var s *session
+			if useTerminal || !terminal.HasDisplay() {
+				var err error
+				s, err = runTerminal(opts, ascii || !terminal.SupportsUnicode())
+				if err != nil {
+					return err
+				}
+			} else {
+				game := NewGame(opts)
+
+				ebiten.SetWindowSize(windowSize(game.screenSize()))
+				ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+				ebiten.SetWindowTitle(Metadata.Title)
+				if err := ebiten.RunGame(game); err != nil {
+					return err
+				}
+				s = game.session
+			}
+
+			// Save the replay once the game is closed, which only holds the last run if it was restarted
+			if recordPath != "" {
+				if err := s.Recording().Save(recordPath); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Saved replay to %s\n", recordPath)
+			}
+
 			return nil
 		},
 	}
 
+	cmd.AddCommand(newEditCommand())
+	cmd.AddCommand(newHistoryCommand())
+
+	cmd.Flags().StringVar(&recordPath, "record", "", "Record every move to a replay file at this path (after restarting, only the last run is saved)")
+	cmd.Flags().StringVar(&replayPath, "replay", "", "Play back the replay file at this path")
+	cmd.Flags().BoolVar(&useTerminal, "terminal", false, "Play in the terminal instead of a window (automatic when there's no display)")
+	cmd.Flags().BoolVar(&ascii, "ascii", false, "Draw the terminal version with plain ASCII characters instead of Unicode")
+	cmd.Flags().StringVar(&player, "player", scores.DefaultPlayer(), "Name to put on the leaderboard")
+	cmd.Flags().StringVar(&generator, "generator", sim.DefaultGenerator, fmt.Sprintf("How to build the starting maze (%s)", strings.Join(sim.Generators(), ", ")))
+	cmd.Flags().IntVar(&width, "width", sim.MazeWidth, fmt.Sprintf("Number of cells the maze is wide (%d-%d)", sim.MinMazeSize, sim.MaxMazeSize))
+	cmd.Flags().IntVar(&height, "height", sim.MazeHeight, fmt.Sprintf("Number of cells the maze is tall (%d-%d)", sim.MinMazeSize, sim.MaxMazeSize))
+	cmd.Flags().IntVar(&cellSize, "cell-size", defaultCellSize, fmt.Sprintf("Size of each cell of the maze in pixels (%d-%d), the window is scaled down if it doesn't fit", minCellSize, maxCellSize))
+	cmd.Flags().IntVar(&packages, "packages", 0, fmt.Sprintf("Play a shift delivering this many packages (up to %d), scored on deliveries, time and moves, instead of a single delivery", sim.MaxPackages))
+	cmd.Flags().BoolVar(&daily, "daily", false, "Play today's daily challenge, the same city for everyone, where only your first attempt of the day is ranked")
+	cmd.Flags().BoolVar(&campaign, "campaign", false, "Play the campaign of hand-made levels, starting from the first one you haven't delivered yet")
+	cmd.Flags().StringVar(&levelPath, "level", "", "Play the level file at this path instead of a generated city")
+	cmd.Flags().IntVar(&capacity, "capacity", sim.DefaultCapacity, "Number of packages the car can carry at once during a shift")
+	cmd.Flags().StringVar(&difficulty, "difficulty", sim.DefaultDifficulty, fmt.Sprintf("How chaotic the city is (%s), each has its own leaderboard", strings.Join(difficultyChoices(), ", ")))
+	cmd.Flags().Float64Var(&wallChance, "wall-chance", normal.WallChance, "Chance of each wall toggling whenever the walls shift, for a custom difficulty (0-1)")
+	cmd.Flags().DurationVar(&wallInterval, "wall-interval", ticksToDuration(normal.WallInterval), "Time between shifts of the walls, for a custom difficulty")
+	cmd.Flags().DurationVar(&customerInterval, "customer-interval", ticksToDuration(normal.CustomerInterval), "Time between moves of the customer, for a custom difficulty")
+	cmd.Flags().DurationVar(&moveCooldown, "move-cooldown", ticksToDuration(normal.MoveCooldown), "Time between moves of the car, for a custom difficulty")
+	cmd.Flags().Float64Var(&deadline, "deadline", normal.Deadline, "Time to deliver in, as a multiple of the shortest route at full speed, for a custom difficulty (0 for none)")
+	cmd.Flags().Float64Var(&fuel, "fuel", normal.Fuel, "Moves of fuel, as a multiple of the shortest route, for a custom difficulty (0 for an endless tank)")
+	cmd.Flags().IntVar(&traffic, "traffic", normal.Traffic, "Number of other cars driving around the maze, for a custom difficulty")
+	cmd.Flags().BoolVar(&crashesEnd, "crashes-end", normal.CrashesEnd, "End the run when crashing into traffic instead of losing time, for a custom difficulty")
+	cmd.Flags().IntVar(&powerUps, "power-ups", normal.PowerUps, "Number of power-ups lying around the maze, for a custom difficulty")
+	cmd.Flags().IntVar(&buffer, "buffer", sim.DefaultConfig().InputBuffer, "Number of presses to remember during the cooldown between moves (0 to ignore them)")
+	cmd.Flags().DurationVar(&repeatDelay, "repeat-delay", 0, "How long to hold a direction before the car keeps moving that way (e.g. 200ms, off by default)")
+	cmd.Flags().DurationVar(&repeatInterval, "repeat-interval", 50*time.Millisecond, "Time between moves while a direction is held, after --repeat-delay")
+	cmd.Flags().Int64Var(&seed, "seed", 0, "Seed for the maze, so everyone using the same seed races the same city (random by default)")
+
 	return cmd
 }
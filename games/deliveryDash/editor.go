@@ -0,0 +1,663 @@
+package deliveryDash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/emmahsax/go-games/games/deliveryDash/sim"
+	"github.com/emmahsax/go-games/input"
+	"github.com/emmahsax/go-games/scores"
+	"github.com/emmahsax/go-games/storage"
+	"github.com/emmahsax/go-games/terminal"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/spf13/cobra"
+)
+
+const (
+	editorMinWidth      = 720  // Narrowest the editor's screen gets, so that its help fits below small levels
+	editorPanelHeight   = 100  // Height of the text below the level
+	defaultRegionChance = 0.3  // Chance of a new region's walls toggling whenever the walls shift
+	chanceStep          = 0.05 // How much a region's chance changes with each press
+	parStep             = 0.5  // How many seconds the par time changes with each press
+)
+
+// Actions of the level editor, on top of the game's own
+const (
+	undoAction       input.Action = "undo"        // Undo the last change
+	redoAction       input.Action = "redo"        // Redo the last change undone
+	saveAction       input.Action = "save"        // Save the level to its file
+	testPlayAction   input.Action = "test-play"   // Play the level, or go back to editing it
+	nextRegionAction input.Action = "next-region" // Paint shifting walls in the next region
+	moreChanceAction input.Action = "more-chance" // Make the region's walls shift more often
+	lessChanceAction input.Action = "less-chance" // Make the region's walls shift less often
+	moreParAction    input.Action = "more-par"    // Give the level a longer par time
+	lessParAction    input.Action = "less-par"    // Give the level a shorter par time
+	closedAction     input.Action = "closed"      // Paint shifting walls starting closed, or open
+)
+
+// tool is what the mouse paints in the level editor
+type tool int
+
+const (
+	streetTool   tool = iota // Streets that are always open
+	wallTool                 // Permanent walls
+	shiftingTool             // Shifting walls in the picked region
+	startTool                // Columns the car can start above
+	customerTool             // Columns the customer moves between
+	hazardTool               // Traffic that starts in a cell, cycling through each behavior
+)
+
+// editorTools are the tools of the level editor, picked with the number keys in this order
+var editorTools = []struct {
+	tool   tool
+	action input.Action
+	name   string
+}{
+	{streetTool, "street-tool", "Street"},
+	{wallTool, "wall-tool", "Permanent wall"},
+	{shiftingTool, "shifting-tool", "Shifting wall"},
+	{startTool, "start-tool", "Start lane"},
+	{customerTool, "customer-tool", "Customer lane"},
+	{hazardTool, "hazard-tool", "Hazard"},
+}
+
+// regionColors are the colors each region of shifting walls is drawn in, in turn
+var regionColors = []color.RGBA{
+	{255, 140, 0, 255},
+	{0, 180, 255, 255},
+	{220, 80, 220, 255},
+	{0, 200, 120, 255},
+	{230, 60, 60, 255},
+	{230, 220, 60, 255},
+}
+
+// editorBindings returns the game's bindings plus the level editor's: Ctrl+Z and Ctrl+Y (or Ctrl+Shift+Z) to undo and
+// redo, Ctrl+S to save, T to test play, 1 to 6 to pick a tool, Tab to pick a region, [ and ] for its chance, - and =
+// for the par time, and O to paint shifting walls open or closed
+func editorBindings() input.Bindings {
+	bindings := DefaultBindings().With(input.Bindings{
+		undoAction:       {Keys: []input.Chord{{ebiten.KeyControl, ebiten.KeyZ}}},
+		redoAction:       {Keys: []input.Chord{{ebiten.KeyControl, ebiten.KeyY}, {ebiten.KeyControl, ebiten.KeyShift, ebiten.KeyZ}}},
+		saveAction:       {Keys: []input.Chord{{ebiten.KeyControl, ebiten.KeyS}}},
+		testPlayAction:   {Keys: input.Keys(ebiten.KeyT)},
+		nextRegionAction: {Keys: input.Keys(ebiten.KeyTab)},
+		moreChanceAction: {Keys: input.Keys(ebiten.KeyBracketRight)},
+		lessChanceAction: {Keys: input.Keys(ebiten.KeyBracketLeft)},
+		moreParAction:    {Keys: input.Keys(ebiten.KeyEqual)},
+		lessParAction:    {Keys: input.Keys(ebiten.KeyMinus)},
+		closedAction:     {Keys: input.Keys(ebiten.KeyO)},
+	})
+	for i, t := range editorTools {
+		bindings[t.action] = input.Binding{Keys: input.Keys(ebiten.KeyDigit1 + ebiten.Key(i))}
+	}
+	return bindings
+}
+
+// editor is the level editor: the mouse paints the level's map, lanes and hazards with the picked tool, every change
+// can be undone, and the level can be test played right there before it's saved
+type editor struct {
+	path     string
+	level    *sim.Level
+	saved    *sim.Level   // Level as it was last loaded or saved, to tell whether it has unsaved changes
+	history  []*sim.Level // Level before each change, to undo it
+	future   []*sim.Level // Changes undone, to redo them
+	tool     tool
+	region   byte   // Region shifting walls are painted in
+	closed   bool   // Whether shifting walls are painted starting closed
+	held     bool   // Whether a mouse button was held on the last frame
+	erasing  bool   // Whether the right mouse button is painting streets instead of the tool
+	anchor   int    // Column a lane is being painted from, or -1
+	message  string // Result of the last save or test play, shown until the next change
+	exiting  bool   // Whether ESC has been pressed once with unsaved changes, and will exit if pressed again
+	input    *input.Handler
+	bindings input.Bindings
+	cellSize int
+	play     *Game // Test play of the level, while it's being played
+}
+
+func newEditor(path string, level *sim.Level, bindings input.Bindings, cellSize int) *editor {
+	return &editor{
+		path:     path,
+		level:    level.Clone(),
+		saved:    level.Clone(),
+		tool:     wallTool,
+		region:   'a',
+		closed:   true,
+		anchor:   -1,
+		input:    input.NewHandler(bindings),
+		bindings: bindings,
+		cellSize: cellSize,
+	}
+}
+
+// newLevel returns an empty level of streets, named after its file, that the car can start and be delivered anywhere
+// along
+func newLevel(path string, width, height int) *sim.Level {
+	level := &sim.Level{
+		Name:     strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Regions:  make(map[string]float64),
+		Start:    sim.Lane{0, width - 1},
+		Customer: sim.Lane{0, width - 1},
+	}
+	for y := 0; y < height; y++ {
+		level.Map = append(level.Map, strings.Repeat(string(sim.LevelStreet), width))
+	}
+	return level
+}
+
+// screenSize returns the size of the editor's screen in pixels: the level plus a border of one cell all the way
+// around it (where the lanes are painted), and the text below
+func (e *editor) screenSize() (int, int) {
+	width, height := e.level.Size()
+	return max(e.cellSize*(width+2), editorMinWidth), e.cellSize*(height+2) + editorPanelHeight
+}
+
+func (e *editor) Update() error {
+	e.input.Update()
+
+	// While test playing, the game has the keyboard to itself, except for going back to editing
+	if e.play != nil {
+		if e.input.JustPressed(testPlayAction) {
+			e.play = nil
+			return nil
+		}
+		if err := e.play.Update(); errors.Is(err, ebiten.Termination) {
+			e.play = nil
+		} else if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// Redo is checked before undo, since holding Ctrl+Shift+Z also holds Ctrl+Z
+	switch {
+	case e.input.JustPressed(input.Back):
+		if e.changed() && !e.exiting {
+			e.exiting = true
+			e.message = "The level has unsaved changes, press ESC again to exit without saving them"
+			return nil
+		}
+		return ebiten.Termination
+	case e.input.JustPressed(redoAction):
+		e.redo()
+	case e.input.JustPressed(undoAction):
+		e.undo()
+	case e.input.JustPressed(saveAction):
+		e.save()
+	case e.input.JustPressed(testPlayAction):
+		e.testPlay()
+	case e.input.JustPressed(nextRegionAction):
+		e.nextRegion()
+	case e.input.JustPressed(moreChanceAction):
+		e.changeChance(chanceStep)
+	case e.input.JustPressed(lessChanceAction):
+		e.changeChance(-chanceStep)
+	case e.input.JustPressed(moreParAction):
+		e.changePar(parStep)
+	case e.input.JustPressed(lessParAction):
+		e.changePar(-parStep)
+	case e.input.JustPressed(closedAction):
+		e.closed = !e.closed
+	}
+	for _, t := range editorTools {
+		if e.input.JustPressed(t.action) {
+			e.tool = t.tool
+		}
+	}
+
+	e.paint()
+	return nil
+}
+
+// paint applies the tool to the cell under the mouse while the left button is held, or paints streets while the right
+// button is, keeping each stroke of the mouse as a single change to undo
+func (e *editor) paint() {
+	left := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	right := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
+	pressed := (left || right) && !e.held
+	if e.held && !left && !right {
+		e.endStroke()
+	}
+	e.held = left || right
+	if !e.held {
+		return
+	}
+
+	width, height := e.level.Size()
+	x, y := e.cellAt(ebiten.CursorPosition())
+	inside := x >= 0 && x < width && y >= 0 && y < height
+	if pressed {
+		e.checkpoint()
+		e.erasing = right
+		e.anchor = -1
+		if x >= 0 && x < width {
+			e.anchor = x
+		}
+	}
+
+	switch {
+	case e.erasing:
+		if inside {
+			e.setCell(x, y, sim.LevelStreet)
+		}
+	case e.tool == startTool || e.tool == customerTool:
+		if e.anchor < 0 {
+			return
+		}
+		column := min(max(x, 0), width-1)
+		lane := sim.Lane{min(e.anchor, column), max(e.anchor, column)}
+		if e.tool == startTool {
+			e.level.Start = lane
+		} else {
+			e.level.Customer = lane
+		}
+	case e.tool == hazardTool:
+		if pressed && inside {
+			e.cycleHazard(x, y)
+		}
+	case inside:
+		e.setCell(x, y, e.brush())
+	}
+
+	e.clearBlockedHazards()
+}
+
+// cellAt returns the cell of the level at a position on the screen, which is outside of it in the border
+func (e *editor) cellAt(screenX, screenY int) (int, int) {
+	return screenX/e.cellSize - 1, screenY/e.cellSize - 1 // -1 for border
+}
+
+// brush returns the cell the tool paints, adding the region of a shifting wall to the level if it's new
+func (e *editor) brush() byte {
+	switch e.tool {
+	case wallTool:
+		return sim.LevelWall
+	case shiftingTool:
+		if _, ok := e.level.Regions[string(e.region)]; !ok {
+			e.level.Regions[string(e.region)] = defaultRegionChance
+		}
+		if e.closed {
+			return e.region - 'a' + 'A'
+		}
+		return e.region
+	default:
+		return sim.LevelStreet
+	}
+}
+
+// setCell changes a single cell of the level's map
+func (e *editor) setCell(x, y int, c byte) {
+	row := []byte(e.level.Map[y])
+	row[x] = c
+	e.level.Map[y] = string(row)
+}
+
+// cycleHazard adds traffic to a cell, changes how it drives, or takes it away again after the last behavior
+func (e *editor) cycleHazard(x, y int) {
+	behaviors := sim.Behaviors()
+	for i, hazard := range e.level.Hazards {
+		if hazard.X != x || hazard.Y != y {
+			continue
+		}
+		if hazard.Behavior == behaviors[len(behaviors)-1] {
+			e.level.Hazards = append(e.level.Hazards[:i], e.level.Hazards[i+1:]...)
+			return
+		}
+		for j, behavior := range behaviors {
+			if behavior == hazard.Behavior {
+				e.level.Hazards[i].Behavior = behaviors[j+1]
+				return
+			}
+		}
+	}
+	e.level.Hazards = append(e.level.Hazards, sim.Hazard{X: x, Y: y, Behavior: behaviors[0]})
+}
+
+// clearBlockedHazards takes away any traffic that a wall has been painted over
+func (e *editor) clearBlockedHazards() {
+	hazards := e.level.Hazards[:0]
+	for _, hazard := range e.level.Hazards {
+		if c := e.level.Map[hazard.Y][hazard.X]; c != sim.LevelWall && (c < 'A' || c > 'Z') {
+			hazards = append(hazards, hazard)
+		}
+	}
+	e.level.Hazards = hazards
+}
+
+// nextRegion picks the next region to paint shifting walls in: each region the level has, then a new one
+func (e *editor) nextRegion() {
+	var regions []string
+	for name := range e.level.Regions {
+		regions = append(regions, name)
+	}
+	sort.Strings(regions)
+	for c := byte('a'); c <= 'z'; c++ {
+		if _, ok := e.level.Regions[string(c)]; !ok {
+			regions = append(regions, string(c))
+			break
+		}
+	}
+
+	for i, name := range regions {
+		if name == string(e.region) {
+			e.region = regions[(i+1)%len(regions)][0]
+			return
+		}
+	}
+	e.region = regions[0][0]
+}
+
+// changeChance changes how often the picked region's walls shift
+func (e *editor) changeChance(step float64) {
+	name := string(e.region)
+	chance, ok := e.level.Regions[name]
+	if !ok {
+		chance = defaultRegionChance
+	}
+
+	e.checkpoint()
+	e.level.Regions[name] = min(max(float64(int((chance+step)*100+0.5))/100, 0), 1) // Rounded to whole percents
+}
+
+// changePar changes the level's par time
+func (e *editor) changePar(step float64) {
+	e.checkpoint()
+	e.level.Par = max(e.level.Par+step, 0)
+}
+
+// checkpoint remembers the level before a change, so that it can be undone
+func (e *editor) checkpoint() {
+	e.history = append(e.history, e.level.Clone())
+	e.future = nil
+	e.message = ""
+	e.exiting = false
+}
+
+// endStroke forgets the checkpoint of a stroke of the mouse that didn't change anything
+func (e *editor) endStroke() {
+	if last := len(e.history) - 1; last >= 0 && sameLevel(e.history[last], e.level) {
+		e.history = e.history[:last]
+	}
+}
+
+// undo takes back the last change
+func (e *editor) undo() {
+	if len(e.history) == 0 {
+		return
+	}
+	e.future = append(e.future, e.level)
+	e.level = e.history[len(e.history)-1]
+	e.history = e.history[:len(e.history)-1]
+	e.message = ""
+}
+
+// redo makes the last change undone again
+func (e *editor) redo() {
+	if len(e.future) == 0 {
+		return
+	}
+	e.history = append(e.history, e.level)
+	e.level = e.future[len(e.future)-1]
+	e.future = e.future[:len(e.future)-1]
+	e.message = ""
+}
+
+// changed reports whether the level has changes that haven't been saved
+func (e *editor) changed() bool {
+	return !sameLevel(e.level, e.saved)
+}
+
+// sameLevel reports whether two levels would be saved the same
+func sameLevel(a, b *sim.Level) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aJSON) == string(bJSON)
+}
+
+// save writes the level to its file, once it can be played, leaving out any regions without walls
+func (e *editor) save() {
+	for name := range e.level.Regions {
+		if !strings.ContainsAny(strings.Join(e.level.Map, ""), name+strings.ToUpper(name)) {
+			delete(e.level.Regions, name)
+		}
+	}
+
+	if err := e.level.Validate(); err != nil {
+		e.message = "Can't save yet: " + err.Error()
+		return
+	}
+	if err := storage.Save(e.path, e.level); err != nil {
+		e.message = "Could not save the level: " + err.Error()
+		return
+	}
+
+	e.saved = e.level.Clone()
+	e.message = "Saved to " + e.path
+	e.exiting = false
+}
+
+// testPlay starts playing the level as it is, on its own difficulty and without putting the run on the leaderboard
+func (e *editor) testPlay() {
+	if err := e.level.Validate(); err != nil {
+		e.message = "Can't test play yet: " + err.Error()
+		return
+	}
+
+	config := sim.DefaultConfig()
+	config.Level = e.level.Clone()
+	config.Difficulty = levelDifficulty(config.Level)
+	e.play = NewGame(Options{
+		Seed:     newSeed(),
+		Config:   config,
+		Practice: true,
+		Player:   scores.DefaultPlayer(),
+		Bindings: e.bindings,
+		CellSize: e.cellSize,
+	})
+	e.play.titleScreen = false
+	e.message = ""
+}
+
+func (e *editor) Draw(screen *ebiten.Image) {
+	if e.play != nil {
+		e.play.Draw(screen)
+		screenWidth, screenHeight := e.play.screenSize()
+		hint := "T: Back to the editor"
+		ebitenutil.DebugPrintAt(screen, hint, screenWidth-len(hint)*6-10, screenHeight-e.cellSize/2-8) // The debug font is 6x16 pixels
+		return
+	}
+
+	cellSize := e.cellSize
+	width, height := e.level.Size()
+	screen.Fill(color.RGBA{50, 50, 50, 255})
+	vector.DrawFilledRect(screen, float32(cellSize), float32(cellSize), float32(width*cellSize), float32(height*cellSize), color.RGBA{30, 30, 30, 255}, false)
+
+	// Draw permanent walls as solid blocks, and shifting walls in the color of their region: filled while they start
+	// closed, and outlined while they start open
+	for y, row := range e.level.Map {
+		for x := 0; x < len(row); x++ {
+			left, top := float32((x+1)*cellSize), float32((y+1)*cellSize) // +1 for border
+			switch c := row[x]; {
+			case c == sim.LevelWall:
+				vector.DrawFilledRect(screen, left+1, top+1, float32(cellSize-2), float32(cellSize-2), color.RGBA{200, 200, 200, 255}, false)
+			case c == sim.LevelStreet:
+				vector.DrawFilledRect(screen, left+float32(cellSize)/2-1, top+float32(cellSize)/2-1, 2, 2, color.RGBA{60, 60, 60, 255}, false)
+			default:
+				region := strings.ToLower(string(c))
+				fill := regionColors[int(region[0]-'a')%len(regionColors)]
+				if c >= 'A' && c <= 'Z' {
+					vector.DrawFilledRect(screen, left+1, top+1, float32(cellSize-2), float32(cellSize-2), fill, false)
+				} else {
+					vector.StrokeRect(screen, left+2, top+2, float32(cellSize-4), float32(cellSize-4), 2, fill, false)
+				}
+				if cellSize >= 16 {
+					ebitenutil.DebugPrintAt(screen, region, int(left)+cellSize/2-3, int(top)+cellSize/2-8)
+				}
+			}
+		}
+	}
+
+	// Draw the lanes in the border, where the car starts and the customer waits
+	for x := e.level.Start[0]; x <= e.level.Start[1]; x++ {
+		vector.DrawFilledRect(screen, float32((x+1)*cellSize), 0, float32(cellSize), float32(cellSize), color.RGBA{0, 255, 0, 255}, false)
+	}
+	for x := e.level.Customer[0]; x <= e.level.Customer[1]; x++ {
+		vector.DrawFilledRect(screen, float32((x+1)*cellSize), float32((height+1)*cellSize), float32(cellSize), float32(cellSize), color.RGBA{0, 0, 255, 255}, false)
+	}
+
+	// Draw the hazards, as a square in the color of the traffic with the initial of how it drives
+	for _, hazard := range e.level.Hazards {
+		x, y := float32((hazard.X+1)*cellSize)+float32(cellSize)/4, float32((hazard.Y+1)*cellSize)+float32(cellSize)/4
+		vector.DrawFilledRect(screen, x, y, float32(cellSize)/2, float32(cellSize)/2, trafficBodyColors[hazard.Behavior], false)
+		ebitenutil.DebugPrintAt(screen, strings.ToUpper(hazard.Behavior.String()[:1]), int(x)+cellSize/4-3, int(y)+cellSize/4-8)
+	}
+
+	// Outline the cell under the mouse
+	if x, y := e.cellAt(ebiten.CursorPosition()); x >= 0 && x < width && y >= 0 && y < height {
+		vector.StrokeRect(screen, float32((x+1)*cellSize), float32((y+1)*cellSize), float32(cellSize), float32(cellSize), 1, color.RGBA{255, 255, 255, 255}, false)
+	}
+
+	for i, line := range e.panelLines() {
+		ebitenutil.DebugPrintAt(screen, line, 10, (height+2)*cellSize+4+i*18)
+	}
+}
+
+// panelLines returns the text shown below the level: the level's details, the tool, whether the level can be played
+// yet, and the keys
+func (e *editor) panelLines() []string {
+	title := "LEVEL EDITOR: " + e.path
+	if e.changed() {
+		title += " (unsaved)"
+	}
+	difficulty := sim.DefaultDifficulty
+	if e.level.Difficulty != "" {
+		difficulty = e.level.Difficulty
+	}
+	title += fmt.Sprintf("   %s on %s, par %.1fs", e.level.Name, difficultyTitle(difficulty), e.level.Par)
+
+	tool := ""
+	for _, t := range editorTools {
+		if t.tool == e.tool {
+			tool = "Tool: " + t.name
+		}
+	}
+	if e.tool == shiftingTool {
+		chance, ok := e.level.Regions[string(e.region)]
+		if !ok {
+			chance = defaultRegionChance
+		}
+		starts := "open"
+		if e.closed {
+			starts = "closed"
+		}
+		tool += fmt.Sprintf(" in region %c (%.0f%% chance of shifting), starting %s", e.region, chance*100, starts)
+	}
+
+	status := e.message
+	if status == "" {
+		status = "Ready to test play and save"
+		if err := e.level.Validate(); err != nil {
+			status = "Not playable yet: " + err.Error()
+		}
+	}
+
+	return []string{
+		title,
+		tool,
+		status,
+		"1-6: Street, Wall, Shifting, Start lane, Customer lane, Hazard   Right-click: Street   Tab: Region",
+		"[ ]: Region chance   O: Open/closed   - =: Par   Ctrl+Z/Y: Undo/Redo   T: Test play   Ctrl+S: Save",
+	}
+}
+
+// Layout returns the size of the editor's screen, or of the game while the level is being test played
+func (e *editor) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if e.play != nil {
+		return e.play.Layout(outsideWidth, outsideHeight)
+	}
+	return e.screenSize()
+}
+
+// newEditCommand returns the command that opens the level editor on a level file, which is created when it's saved if
+// it doesn't exist yet
+func newEditCommand() *cobra.Command {
+	var width, height, cellSize int
+	var name, description, difficulty string
+	var par float64
+
+	cmd := &cobra.Command{
+		Use:   "edit <level file>",
+		Short: "Make a level of your own in the level editor, or change one, to play with --level",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !terminal.HasDisplay() {
+				return fmt.Errorf("the level editor needs a display to open its window on")
+			}
+			if cellSize < minCellSize || cellSize > maxCellSize {
+				return fmt.Errorf("--cell-size must be between %d and %d pixels", minCellSize, maxCellSize)
+			}
+			if _, ok := sim.DifficultyPreset(difficulty); difficulty != "" && !ok {
+				return fmt.Errorf("unknown difficulty %q (choose from %s)", difficulty, strings.Join(sim.Difficulties(), ", "))
+			}
+			if par < 0 {
+				return fmt.Errorf("--par can't be negative")
+			}
+
+			path := args[0]
+			level, err := sim.LoadLevel(path)
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				if width < sim.MinMazeSize || width > sim.MaxMazeSize || height < sim.MinMazeSize || height > sim.MaxMazeSize {
+					return fmt.Errorf("--width and --height must be between %d and %d cells", sim.MinMazeSize, sim.MaxMazeSize)
+				}
+				level = newLevel(path, width, height)
+			case err != nil:
+				return err
+			case cmd.Flags().Changed("width") || cmd.Flags().Changed("height"):
+				return fmt.Errorf("--width and --height are only for new levels, and %s already exists", path)
+			}
+
+			if cmd.Flags().Changed("name") {
+				level.Name = name
+			}
+			if cmd.Flags().Changed("description") {
+				level.Description = description
+			}
+			if cmd.Flags().Changed("difficulty") {
+				level.Difficulty = difficulty
+			}
+			if cmd.Flags().Changed("par") {
+				level.Par = par
+			}
+
+			bindings, err := input.Load(editorBindings())
+			if err != nil {
+				return err
+			}
+
+			e := newEditor(path, level, bindings, cellSize)
+			ebiten.SetWindowSize(windowSize(e.screenSize()))
+			ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+			ebiten.SetWindowTitle("Delivery Dash Level Editor")
+			return ebiten.RunGame(e)
+		},
+	}
+
+	cmd.Flags().IntVar(&width, "width", sim.MazeWidth, fmt.Sprintf("Number of cells a new level is wide (%d-%d)", sim.MinMazeSize, sim.MaxMazeSize))
+	cmd.Flags().IntVar(&height, "height", sim.MazeHeight, fmt.Sprintf("Number of cells a new level is tall (%d-%d)", sim.MinMazeSize, sim.MaxMazeSize))
+	cmd.Flags().IntVar(&cellSize, "cell-size", defaultCellSize, fmt.Sprintf("Size of each cell of the level in pixels (%d-%d)", minCellSize, maxCellSize))
+	cmd.Flags().StringVar(&name, "name", "", "Name of the level (the file's name for a new level)")
+	cmd.Flags().StringVar(&description, "description", "", "Description of the level, shown on the title screen")
+	cmd.Flags().StringVar(&difficulty, "difficulty", "", fmt.Sprintf("Difficulty to play the level on (%s), or empty for %s", strings.Join(sim.Difficulties(), ", "), sim.DefaultDifficulty))
+	cmd.Flags().Float64Var(&par, "par", 0, "Seconds a good delivery of the level takes (0 for none)")
+
+	return cmd
+}
@@ -0,0 +1,67 @@
+package deliveryDash
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/emmahsax/go-games/games/deliveryDash/sim"
+)
+
+const ghostAlpha = 0.4 // How solid the ghost car is drawn, from 0 to 1
+
+// ghostFrame is where a car got to during a run: the milliseconds on the clock, its cell and the way it was facing
+type ghostFrame [4]int
+
+// car returns the car as it was in the frame
+func (f ghostFrame) car() sim.Car {
+	return sim.Car{CellX: f[1], CellY: f[2], Direction: sim.Direction(f[3])}
+}
+
+// ghostPath is every place a car got to during a run, in order, which is kept with the run on the leaderboard so
+// that later runs on the same seed can race a ghost of it. Frames are kept by the time on the clock rather than the
+// tick, so the ghost sets off when the player enters the maze, however long they wait before they do.
+type ghostPath []ghostFrame
+
+// record adds where the car is at a time on the clock, if it has moved or turned since the last frame
+func (p *ghostPath) record(elapsed time.Duration, car sim.Car) {
+	frame := ghostFrame{int(elapsed.Milliseconds()), car.CellX, car.CellY, int(car.Direction)}
+	if n := len(*p); n > 0 && (*p)[n-1][1] == frame[1] && (*p)[n-1][2] == frame[2] && (*p)[n-1][3] == frame[3] {
+		return
+	}
+	*p = append(*p, frame)
+}
+
+// at returns where the car was at a time on the clock, once it had entered the maze
+func (p ghostPath) at(elapsed time.Duration) sim.Car {
+	i := sort.Search(len(p), func(i int) bool { return p[i][0] > int(elapsed.Milliseconds()) })
+	return p[max(i-1, 0)].car()
+}
+
+// reached returns the time on the clock the car first got as far down the maze as a row, and false if it never did
+func (p ghostPath) reached(row int) (time.Duration, bool) {
+	for _, frame := range p {
+		if frame[2] >= row {
+			return time.Duration(frame[0]) * time.Millisecond, true
+		}
+	}
+	return 0, false
+}
+
+// encode returns the path as it's kept on the leaderboard, or nothing if it can't be
+func (p ghostPath) encode() json.RawMessage {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// decodeGhost reads a path kept on the leaderboard, returning false if there isn't one to race
+func decodeGhost(data json.RawMessage) (ghostPath, bool) {
+	var path ghostPath
+	if len(data) == 0 || json.Unmarshal(data, &path) != nil || len(path) == 0 {
+		return nil, false
+	}
+	return path, true
+}
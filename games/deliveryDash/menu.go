@@ -0,0 +1,156 @@
+package deliveryDash
+
+// menuAction is a single press while the pause menu is open
+type menuAction int
+
+const (
+	menuUp menuAction = iota
+	menuDown
+	menuConfirm
+	menuBack
+)
+
+// menuChoice is what the player picked from the pause menu
+type menuChoice int
+
+const (
+	choiceNone menuChoice = iota
+	choiceResume
+	choiceRestartSameSeed
+	choiceRestartNewMaze
+	choiceSettings
+	choiceQuitToTitle
+	choiceExit
+)
+
+// settings are the options the player can change from the pause menu, which last until the game is closed
+type settings struct {
+	hideTimer bool // Whether to hide the running time while playing
+}
+
+type menuItem struct {
+	label  string
+	choice menuChoice
+}
+
+var pauseMenuItems = []menuItem{
+	{label: "Resume", choice: choiceResume},
+	{label: "Restart (same seed)", choice: choiceRestartSameSeed},
+	{label: "Restart (new maze)", choice: choiceRestartNewMaze},
+	{label: "Settings", choice: choiceSettings},
+	{label: "Quit to title screen", choice: choiceQuitToTitle},
+	{label: "Exit", choice: choiceExit},
+}
+
+// pauseMenu is the overlay opened with ESC or P, which freezes the world (and so the timer, walls and customer)
+// because nothing steps it while the menu is open
+type pauseMenu struct {
+	open       bool
+	inSettings bool // Whether the settings page is showing instead of the main page
+	selected   int  // Index of the highlighted item on the current page
+	settings   settings
+}
+
+// show opens the menu with the first item highlighted
+func (m *pauseMenu) show() {
+	m.open = true
+	m.inSettings = false
+	m.selected = 0
+}
+
+// press handles a single press while the menu is open, returning what the player picked (if anything). Picking
+// anything other than Settings closes the menu.
+func (m *pauseMenu) press(action menuAction) menuChoice {
+	count := len(pauseMenuItems)
+	if m.inSettings {
+		count = len(m.settingsLines())
+	}
+
+	switch action {
+	case menuUp:
+		m.selected = (m.selected + count - 1) % count
+	case menuDown:
+		m.selected = (m.selected + 1) % count
+	case menuBack:
+		if m.inSettings {
+			m.leaveSettings()
+			return choiceNone
+		}
+		m.open = false
+		return choiceResume
+	case menuConfirm:
+		if m.inSettings {
+			m.pressSetting()
+			return choiceNone
+		}
+
+		choice := pauseMenuItems[m.selected].choice
+		if choice == choiceSettings {
+			m.inSettings = true
+			m.selected = 0
+			return choiceNone
+		}
+		m.open = false
+		return choice
+	}
+
+	return choiceNone
+}
+
+// pressSetting changes the highlighted setting, where the last line of the settings page goes back
+func (m *pauseMenu) pressSetting() {
+	switch m.selected {
+	case 0:
+		m.settings.hideTimer = !m.settings.hideTimer
+	default:
+		m.leaveSettings()
+	}
+}
+
+func (m *pauseMenu) leaveSettings() {
+	m.inSettings = false
+	for i, item := range pauseMenuItems {
+		if item.choice == choiceSettings {
+			m.selected = i
+		}
+	}
+}
+
+func (m *pauseMenu) settingsLines() []string {
+	return []string{
+		"Show timer: " + onOff(!m.settings.hideTimer),
+		"Back",
+	}
+}
+
+// lines returns the text of the menu, with the highlighted item marked
+func (m *pauseMenu) lines() []string {
+	title := "PAUSED"
+	labels := make([]string, 0, len(pauseMenuItems))
+	if m.inSettings {
+		title = "SETTINGS"
+		labels = m.settingsLines()
+	} else {
+		for _, item := range pauseMenuItems {
+			labels = append(labels, item.label)
+		}
+	}
+
+	lines := []string{title, ""}
+	for i, label := range labels {
+		marker := "  "
+		if i == m.selected {
+			marker = "> "
+		}
+		lines = append(lines, marker+label)
+	}
+
+	return append(lines, "", "Up/Down to choose, ENTER to select, ESC to go back")
+}
+
+func onOff(on bool) string {
+	if on {
+		return "On"
+	}
+	return "Off"
+}
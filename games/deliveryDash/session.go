@@ -0,0 +1,669 @@
+package deliveryDash
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/emmahsax/go-games/games/deliveryDash/sim"
+	"github.com/emmahsax/go-games/input"
+	"github.com/emmahsax/go-games/scores"
+)
+
+// Options controls how a game is set up
+type Options struct {
+	Seed     int64          // Seed for the maze, shifting walls and moving customer
+	Config   sim.Config     // Size of the maze and how the world handles input, like buffering presses
+	Custom   sim.Difficulty // Difficulty offered on the title screen after the presets, when it isn't one of them
+	Record   bool           // Record every tick's input so the run can be saved as a replay
+	Playback *sim.Replay    // Play this replay back instead of reading the keyboard (its seed replaces Seed)
+	Player   string         // Name to record scores under
+	Campaign []*sim.Level   // Levels of the campaign, when playing it (the one being played is in Config)
+	Practice bool           // Keep runs off the leaderboard, like test plays in the level editor
+	Daily    string         // Date of the daily challenge being played (e.g. 2006-01-02), or empty when it isn't
+	Bindings input.Bindings // Keys and gamepad buttons for every action
+	CellSize int            // Size of each cell of the maze in pixels, in the window (0 for the default)
+}
+
+// session is everything about a run that doesn't depend on how it's shown: the world, recording and playing back
+// replays, and the leaderboard. Both the window and the terminal drive a session.
+type session struct {
+	opts         Options // Options the session was created with, to restart it
+	world        *sim.World
+	recording    *sim.Replay       // Every tick's input so far, when recording
+	playback     *sim.Replay       // Replay being played back instead of reading the keyboard
+	playbackTick int               // Index of the next input to play back
+	player       string            // Name to record scores under
+	scoreSaved   bool              // Whether the finished run has been put on the leaderboard
+	scoreRank    int               // Rank of the finished run on the leaderboard, or 0 if it didn't place
+	topScores    []scores.Entry    // Leaderboard to show on the win screen
+	scoreErr     error             // Why the finished run couldn't be saved, if it couldn't
+	personalBest scores.Entry      // Player's best run, including the one just finished
+	newBest      bool              // Whether the finished run is the player's new personal best
+	progress     *campaignProgress // How far through the campaign the player has got, when playing it
+	progressErr  error             // Why the campaign's progress couldn't be loaded or saved, if it couldn't
+	unlocked     bool              // Whether delivering the level just unlocked the next one of the campaign
+	daily        *dailyHistory     // Ranked attempts at the daily challenge, when playing it
+	dailyErr     error             // Why the daily challenge's history couldn't be loaded or saved, if it couldn't
+	dailyStarted bool              // Whether the ranked attempt at the daily challenge has been recorded
+	dailyOver    bool              // Whether the ranked attempt at the daily challenge has been recorded with its result
+	path         ghostPath         // Everywhere the car has been so far, kept with the run on the leaderboard
+	ghost        ghostPath         // Path of the player's best run on this seed, to race, or nil if there isn't one
+	deepest      int               // Furthest row down the maze the car has got, for splits against the ghost
+	split        time.Duration     // How far behind the ghost the car was when it last got further down the maze
+	hasSplit     bool              // Whether the car has got anywhere the ghost did yet
+}
+
+func newSession(opts Options) *session {
+	s := &session{
+		opts:    opts,
+		world:   sim.New(opts.Seed, opts.Config),
+		player:  opts.Player,
+		deepest: -1, // The start, above the maze
+	}
+
+	if opts.Playback != nil {
+		// Replays use the seed and config they were recorded with
+		s.world = sim.New(opts.Playback.Seed, opts.Playback.Config)
+		s.playback = opts.Playback
+	} else if opts.Record {
+		s.recording = &sim.Replay{GameVersion: Metadata.Version, Seed: opts.Seed, Config: s.world.Config()}
+	}
+
+	if len(opts.Campaign) > 0 {
+		s.progress, s.progressErr = loadProgress()
+	}
+
+	// Only the first attempt at the daily challenge is ranked, and every one after it is practice
+	if opts.Daily != "" {
+		s.daily, s.dailyErr = loadDailyHistory()
+		if _, played := s.dailyAttempt(); played {
+			s.opts.Practice = true
+		}
+	}
+
+	if s.playback == nil {
+		s.loadGhost()
+	}
+	s.path.record(0, s.world.Car())
+
+	return s
+}
+
+// withLevel returns a fresh session on a new seed playing a level of the campaign, on the level's own difficulty
+func (s *session) withLevel(i int) *session {
+	opts := s.opts
+	opts.Seed = newSeed()
+	opts.Config.Level = opts.Campaign[i]
+	opts.Config.Difficulty = levelDifficulty(opts.Campaign[i])
+
+	return newSession(opts)
+}
+
+// campaignLevel returns the index of the level being played in the campaign, or -1 when not playing it
+func (s *session) campaignLevel() int {
+	for i, level := range s.opts.Campaign {
+		if level == s.world.Config().Level {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextLevel returns the index of the level of the campaign unlocked by delivering this one, or -1 if there isn't one
+func (s *session) nextLevel() int {
+	current := s.campaignLevel()
+	if current < 0 || !s.world.Won() || current+1 >= len(s.opts.Campaign) {
+		return -1
+	}
+	return current + 1
+}
+
+// pick returns a fresh session with the next choice (or the previous one, for a negative step) on the title screen:
+// the next unlocked level when playing the campaign, or the next difficulty otherwise. The daily challenge is the same
+// for everyone, so it has nothing to pick and the window and terminal both go through here to keep it that way.
+func (s *session) pick(step int) *session {
+	if s.opts.Daily != "" {
+		return s // The daily challenge's difficulty is the same for everyone
+	}
+
+	current := s.campaignLevel()
+	if current < 0 || s.progress == nil {
+		return s.cycleDifficulty(step)
+	}
+
+	var unlocked []int
+	for i := range s.opts.Campaign {
+		if s.progress.unlocked(s.opts.Campaign, i) {
+			unlocked = append(unlocked, i)
+		}
+	}
+	for i, level := range unlocked {
+		if level == current {
+			return s.withLevel(unlocked[(i+step+len(unlocked))%len(unlocked)])
+		}
+	}
+	return s
+}
+
+// withDifficulty returns a fresh session on the same seed as this one, with the difficulty of the city changed
+func (s *session) withDifficulty(difficulty sim.Difficulty) *session {
+	opts := s.opts
+	opts.Seed = s.world.Seed()
+	opts.Config.Difficulty = difficulty
+
+	return newSession(opts)
+}
+
+// cycleDifficulty returns a fresh session on the same seed as this one, with the next difficulty (or the previous one,
+// for a negative step) of the ones that can be picked on the title screen: every preset, then the custom one
+func (s *session) cycleDifficulty(step int) *session {
+	var choices []sim.Difficulty
+	for _, name := range sim.Difficulties() {
+		preset, _ := sim.DifficultyPreset(name)
+		choices = append(choices, preset)
+	}
+	if s.opts.Custom.Name() == sim.CustomDifficulty {
+		choices = append(choices, s.opts.Custom)
+	}
+
+	current := 0
+	for i, choice := range choices {
+		if choice == s.world.Config().Difficulty {
+			current = i
+		}
+	}
+
+	return s.withDifficulty(choices[(current+step+len(choices))%len(choices)])
+}
+
+// restart returns a fresh session on the same seed as this one, or on a new seed (which stops playing back a replay).
+// Once a level of the campaign is delivered, a new seed moves on to the next level, and the daily challenge always
+// keeps its seed.
+func (s *session) restart(sameSeed bool) *session {
+	if next := s.nextLevel(); !sameSeed && next >= 0 {
+		return s.withLevel(next)
+	}
+
+	opts := s.opts
+	opts.Seed = s.world.Seed()
+	if !sameSeed && opts.Daily == "" {
+		opts.Seed = newSeed()
+		opts.Playback = nil
+	}
+
+	return newSession(opts)
+}
+
+// newSeed picks a random seed for a new maze
+func newSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// Recording returns the replay recorded so far, or nil if the game isn't recording
+func (s *session) Recording() *sim.Replay {
+	return s.recording
+}
+
+// step advances the world by one tick with the player's input, or with the next input of the replay being played back
+func (s *session) step(in sim.Input) {
+	if s.playback != nil {
+		// Play back the recorded input, standing still once the replay runs out
+		if s.playbackFinished() {
+			return
+		}
+		in = s.playback.Inputs[s.playbackTick]
+		s.playbackTick++
+	}
+
+	if s.recording != nil && !s.world.Won() && !s.world.GameOver() {
+		s.recording.Record(in)
+	}
+
+	s.world.Step(in)
+
+	if s.opts.Daily != "" && !s.opts.Practice && s.playback == nil {
+		s.recordDaily()
+	}
+
+	s.path.record(s.world.Elapsed(), s.world.Car())
+	s.splitGhost()
+
+	// Put the run on the leaderboard as soon as the delivery is made (replays have already been counted)
+	if s.world.Won() && !s.scoreSaved && s.playback == nil && !s.opts.Practice {
+		s.saveScore()
+		s.saveProgress()
+	}
+}
+
+// playbackFinished reports whether a replay is being played back and has run out of input
+func (s *session) playbackFinished() bool {
+	return s.playback != nil && s.playbackTick >= len(s.playback.Inputs)
+}
+
+// saveScore records the finished run on the leaderboard and remembers the top scores for the win screen
+func (s *session) saveScore() {
+	s.scoreSaved = true
+
+	board, err := scores.Load(gameName)
+	if err != nil {
+		s.scoreErr = err
+		return
+	}
+
+	difficulty := s.leaderboard()
+	previousBest, hadBest := board.PersonalBest(s.player, difficulty)
+
+	s.scoreRank = board.Add(scores.Entry{
+		Player:     s.player,
+		Date:       time.Now(),
+		Time:       s.world.Elapsed(),
+		Score:      s.world.Score(),
+		Moves:      s.world.Moves(),
+		Seed:       s.world.Seed(),
+		Difficulty: difficulty,
+		Ghost:      s.path.encode(),
+	})
+	s.topScores = board.Top(difficulty)
+	s.personalBest, _ = board.PersonalBest(s.player, difficulty)
+	s.newBest = !hadBest || s.world.Score() > previousBest.Score ||
+		(s.world.Score() == previousBest.Score && s.world.Elapsed() < previousBest.Time)
+
+	s.scoreErr = board.Save()
+}
+
+// saveProgress records a delivered level of the campaign, unlocking the next one
+func (s *session) saveProgress() {
+	if s.progress == nil || s.campaignLevel() < 0 {
+		return
+	}
+
+	next := s.nextLevel()
+	s.unlocked = next >= 0 && !s.progress.unlocked(s.opts.Campaign, next)
+	s.progress.record(s.world.Config().Level, s.world.Elapsed())
+	s.progressErr = s.progress.save()
+}
+
+// loadGhost finds the player's best run on this seed, to race a ghost of it. Racing a ghost is only an extra, so a
+// leaderboard that can't be read just means there's no ghost.
+func (s *session) loadGhost() {
+	board, err := scores.Load(gameName)
+	if err != nil {
+		return
+	}
+	if best, ok := board.SeedBest(s.player, s.leaderboard(), s.world.Seed()); ok {
+		s.ghost, _ = decodeGhost(best.Ghost)
+	}
+}
+
+// ghostCar returns where the ghost of the player's best run on this seed is, and false if there's no ghost to race.
+// The ghost waits at the start until the car enters the maze, and sets off with it.
+func (s *session) ghostCar() (sim.Car, bool) {
+	if s.ghost == nil {
+		return sim.Car{}, false
+	}
+	if !s.world.Started() {
+		return s.ghost[0].car(), true
+	}
+	return s.ghost.at(s.world.Elapsed()), true
+}
+
+// splitGhost compares the time the car took to get further down the maze than ever before with the time the ghost
+// took to get as far. Shifts go back and forth across the maze, so they're raced without splits.
+func (s *session) splitGhost() {
+	row := s.world.Car().CellY
+	if s.ghost == nil || s.world.Shift() || !s.world.Started() || row <= s.deepest {
+		return
+	}
+
+	s.deepest = row
+	if reached, ok := s.ghost.reached(row); ok {
+		s.split, s.hasSplit = s.world.Elapsed()-reached, true
+	}
+}
+
+// dailyAttempt returns the player's ranked attempt at today's daily challenge, and false if they haven't made it yet
+func (s *session) dailyAttempt() (dailyAttempt, bool) {
+	if s.daily == nil {
+		return dailyAttempt{}, false
+	}
+	return s.daily.attempt(s.player, s.opts.Daily)
+}
+
+// recordDaily keeps the ranked attempt at the daily challenge in the history: as abandoned as soon as the car enters
+// the maze, so that quitting part of the way through doesn't earn another attempt, and with its result once it's over
+func (s *session) recordDaily() {
+	switch {
+	case s.daily == nil || !s.world.Started() || s.dailyOver:
+		return
+	case s.finished():
+		s.dailyOver = true
+	case s.dailyStarted:
+		return
+	}
+	s.dailyStarted = true
+
+	s.daily.record(dailyAttempt{
+		Date:       s.opts.Daily,
+		Player:     s.player,
+		Difficulty: s.world.Config().Difficulty.Name(),
+		Time:       s.world.Elapsed(),
+		Moves:      s.world.Moves(),
+		Delivered:  s.world.Won(),
+		Failure:    s.world.Failure(),
+	})
+	s.dailyErr = s.daily.save()
+}
+
+// dailyLines returns the lines about the daily challenge: whether this attempt is ranked, and the player's streak
+func (s *session) dailyLines() []string {
+	line := fmt.Sprintf("Daily challenge %s: ranked attempt", s.opts.Daily)
+	if s.opts.Practice {
+		line = fmt.Sprintf("Daily challenge %s: practice", s.opts.Daily)
+		if attempt, played := s.dailyAttempt(); played {
+			line += " (ranked attempt: " + attempt.result() + ")"
+		}
+	}
+	lines := []string{line}
+
+	switch {
+	case s.dailyErr != nil:
+		lines = append(lines, "Could not keep your daily history: "+s.dailyErr.Error())
+	case s.daily != nil:
+		current, longest := s.daily.streaks(s.player, s.opts.Daily)
+		lines = append(lines, fmt.Sprintf("Streak: %d days (longest %d)", current, longest))
+	}
+
+	return lines
+}
+
+// leaderboard returns the difficulty the run is ranked under: its preset, or custom for any other settings or a maze
+// that isn't the usual size. Normal runs keep the leaderboard from before there were difficulties, shifts are ranked
+// by score on leaderboards of their own for each number of packages (e.g. shift-5, shift-5-chaotic), and each level
+// has its own leaderboards, where the level's own difficulty goes unnamed (e.g. level-roadworks, level-roadworks-relaxed).
+// Each day's daily challenge has a leaderboard of its own (e.g. daily-2006-01-02).
+func (s *session) leaderboard() string {
+	if s.opts.Daily != "" {
+		return dailyLeaderboard + "-" + s.opts.Daily
+	}
+
+	config := s.world.Config()
+	name := config.Difficulty.Name()
+	if (config.Level == nil && (config.Width != sim.MazeWidth || config.Height != sim.MazeHeight)) || (config.Packages > 0 && config.Capacity != sim.DefaultCapacity) {
+		name = sim.CustomDifficulty
+	}
+
+	var parts []string
+	if config.Packages > 0 {
+		parts = append(parts, fmt.Sprintf("%s-%d", shiftLeaderboard, config.Packages))
+	}
+	unnamed := sim.DefaultDifficulty
+	if config.Level != nil {
+		parts = append(parts, levelLeaderboard(config.Level))
+		unnamed = levelDifficulty(config.Level).Name()
+	}
+	if name != unnamed {
+		parts = append(parts, name)
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// difficultyTitle returns the name of a difficulty as it's shown to players (e.g. Nightmare)
+func difficultyTitle(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// finished reports whether the run is over, either delivered or failed
+func (s *session) finished() bool {
+	return s.world.Won() || s.world.GameOver()
+}
+
+// resultLines returns the results shown once the run is over: the time, the moves taken compared to the shortest
+// possible route (or the deliveries and score of a shift), and the player's personal best
+func (s *session) resultLines() []string {
+	title := "DELIVERED!"
+	if s.world.Shift() {
+		title = "SHIFT COMPLETE!"
+	}
+	if s.world.GameOver() {
+		title = "DELIVERY FAILED: " + strings.ToUpper(failureMessage(s.world.Failure()))
+	}
+
+	// The walls keep shifting, so this is the shortest route through the maze as it was when the run started
+	shortest := "blocked"
+	if s.world.ShortestPath() >= 0 {
+		shortest = fmt.Sprintf("%d", s.world.ShortestPath())
+	}
+	moves := fmt.Sprintf("Moves: %d (shortest route at the start: %s)", s.world.Moves(), shortest)
+	if s.world.Shift() {
+		moves = fmt.Sprintf("Moves: %d", s.world.Moves()) // A shift has no single route to compare against
+	}
+
+	lines := []string{
+		title,
+		"Difficulty: " + difficultyTitle(s.world.Config().Difficulty.Name()),
+		"Time:  " + scores.FormatTime(s.world.Elapsed()),
+		moves,
+	}
+	if s.world.Shift() {
+		lines = append(lines,
+			fmt.Sprintf("Delivered: %d/%d", s.world.Delivered(), len(s.world.Packages())),
+			fmt.Sprintf("Score: %d", s.world.Score()),
+		)
+	}
+	if len(s.world.Traffic()) > 0 {
+		lines = append(lines, fmt.Sprintf("Crashes: %d", s.world.Crashes()))
+	}
+
+	if level := s.world.Config().Level; level != nil {
+		lines = append(lines, s.levelLines(level)...)
+	}
+	if s.opts.Daily != "" {
+		lines = append(lines, s.dailyLines()...)
+	}
+
+	if _, height := s.world.Size(); s.hasSplit && s.world.Won() && s.deepest >= height {
+		lines = append(lines, fmt.Sprintf("Against your ghost: %+.2fs", s.split.Seconds()))
+	}
+
+	if s.scoreSaved && s.scoreErr == nil {
+		best := "Personal best: " + scores.FormatTime(s.personalBest.Time)
+		if s.world.Shift() {
+			best = fmt.Sprintf("Personal best: %d points", s.personalBest.Score)
+		}
+		if s.newBest {
+			best += " (NEW PERSONAL BEST!)"
+		}
+		lines = append(lines, best)
+	}
+
+	again := "R: Play again on a new maze"
+	switch {
+	case s.nextLevel() >= 0:
+		again = "R: Play the next level"
+	case s.opts.Daily != "":
+		again = "R: Practice the daily challenge"
+	}
+	return append(lines, "", again+"   Shift+R: Retry the same seed")
+}
+
+// levelLines returns the results of playing a level: its name (and place in the campaign), its par time, and what
+// delivering it unlocked
+func (s *session) levelLines(level *sim.Level) []string {
+	current := s.campaignLevel()
+	name := "Level: " + level.Name
+	if current >= 0 {
+		name = fmt.Sprintf("Level %d/%d: %s", current+1, len(s.opts.Campaign), level.Name)
+	}
+	lines := []string{name}
+
+	if level.Par > 0 {
+		par := fmt.Sprintf("Par: %.2fs", level.Par)
+		if s.world.Won() && beatPar(level, s.world.Elapsed()) {
+			par += " (beaten!)"
+		}
+		lines = append(lines, par)
+	}
+
+	switch {
+	case current < 0 || !s.world.Won() || s.playback != nil:
+	case s.progressErr != nil:
+		lines = append(lines, "Could not save your progress: "+s.progressErr.Error())
+	case s.unlocked:
+		lines = append(lines, "Unlocked the next level: "+s.opts.Campaign[s.nextLevel()].Name+"!")
+	case s.nextLevel() < 0:
+		lines = append(lines, "Campaign complete!")
+	}
+
+	return lines
+}
+
+// failureMessage explains why a delivery failed
+func failureMessage(failure sim.Failure) string {
+	switch failure {
+	case sim.OutOfFuel:
+		return "you ran out of fuel"
+	case sim.MissedDeadline:
+		return "you missed the deadline"
+	case sim.Crashed:
+		return "you crashed into traffic"
+	default:
+		return "the delivery failed"
+	}
+}
+
+// fuelGauge returns how full the car's tank is, from 0 to 1, and false if it never runs out
+func (s *session) fuelGauge() (float64, bool) {
+	fuel, tank := s.world.Fuel()
+	if tank == 0 {
+		return 0, false
+	}
+	return float64(fuel) / float64(tank), true
+}
+
+// deadlineGauge returns how much of the time to deliver in is left, from 0 to 1, and false if there's no deadline
+func (s *session) deadlineGauge() (float64, bool) {
+	deadline := s.world.Deadline()
+	if deadline == 0 {
+		return 0, false
+	}
+	return max(1-float64(s.world.Elapsed())/float64(deadline), 0), true
+}
+
+// hudLabels returns the text shown beside the fuel and deadline gauges
+func (s *session) hudLabels() (string, string) {
+	fuel, tank := s.world.Fuel()
+	return fmt.Sprintf("Fuel %d/%d", fuel, tank), fmt.Sprintf("Time left %.1fs", max(s.world.Deadline()-s.world.Elapsed(), 0).Seconds())
+}
+
+// powerUpColors are the colors each power-up is drawn in
+var powerUpColors = map[sim.PowerUp]color.RGBA{
+	sim.Freeze:    {120, 200, 255, 255},
+	sim.Bulldozer: {255, 160, 0, 255},
+	sim.Turbo:     {0, 220, 80, 255},
+	sim.Radar:     {220, 80, 220, 255},
+}
+
+// powerUpName returns the name of a power-up as it's shown to players
+func powerUpName(powerUp sim.PowerUp) string {
+	switch powerUp {
+	case sim.Freeze:
+		return "Freeze"
+	case sim.Bulldozer:
+		return "Bulldozer"
+	case sim.Turbo:
+		return "Turbo"
+	case sim.Radar:
+		return "Radar"
+	default:
+		return "None"
+	}
+}
+
+// packageColors are the colors each package of a shift and its customer are drawn in, in turn
+var packageColors = []color.RGBA{
+	{0, 120, 255, 255},
+	{255, 100, 180, 255},
+	{150, 90, 255, 255},
+	{0, 200, 160, 255},
+	{255, 220, 60, 255},
+}
+
+// inventoryLine returns the packages delivered and being carried during a shift, the power-up the car is carrying,
+// and the power-ups in use
+func (s *session) inventoryLine() string {
+	line := "Power-up: " + powerUpName(s.world.Carrying())
+	if s.world.Shift() {
+		var carrying []string
+		for i, p := range s.world.Packages() {
+			if p.State == sim.Carried {
+				carrying = append(carrying, fmt.Sprintf("#%d", i+1))
+			}
+		}
+		if len(carrying) == 0 {
+			carrying = append(carrying, "nothing")
+		}
+		line = fmt.Sprintf("Delivered %d/%d, carrying %s   %s", s.world.Delivered(), len(s.world.Packages()), strings.Join(carrying, " "), line)
+	}
+	if s.world.Carrying() != sim.NoPowerUp {
+		line += " (E to use)"
+	}
+
+	if frozen := s.world.Frozen(); frozen > 0 {
+		line += fmt.Sprintf("   Frozen %.1fs", frozen.Seconds())
+	}
+	if turbo := s.world.Turbo(); turbo > 0 {
+		line += fmt.Sprintf("   Turbo %.1fs", turbo.Seconds())
+	}
+	if s.world.Bulldozing() {
+		line += "   Bulldozing"
+	}
+	if s.world.Radar() != nil {
+		line += "   Radar"
+	}
+
+	return line
+}
+
+// statusLine returns the line of text shown above the maze: the time, and how the run ended
+func (s *session) statusLine(set settings) string {
+	switch {
+	case s.world.GameOver():
+		return "Game Over: " + failureMessage(s.world.Failure()) + " - Press R to try again, ESC for the menu"
+	case s.world.Won():
+		return fmt.Sprintf("Total Time: %.2f seconds (seed %d) - Press R to play again, ESC for the menu", s.world.Elapsed().Seconds(), s.world.Seed())
+	case s.playbackFinished():
+		return fmt.Sprintf("Replay finished at %.2f seconds - Press ESC for the menu", s.world.Elapsed().Seconds())
+	case s.world.Started() && !set.hideTimer && s.hasSplit:
+		return fmt.Sprintf("Time: %.2f (%+.2f against your ghost) - Press ESC to pause", s.world.Elapsed().Seconds(), s.split.Seconds())
+	case s.world.Started() && !set.hideTimer:
+		return fmt.Sprintf("Time: %.2f - Press ESC to pause", s.world.Elapsed().Seconds())
+	default:
+		return "Press ESC to pause"
+	}
+}
+
+// leaderboardHeadline returns the line shown above the leaderboard once the delivery is made
+func (s *session) leaderboardHeadline() string {
+	switch {
+	case s.scoreErr != nil:
+		return "Could not save your score: " + s.scoreErr.Error()
+	case s.scoreRank == 0:
+		return fmt.Sprintf("You didn't make the top %d this time", scores.MaxEntries)
+	case s.scoreRank == 1:
+		return "NEW RECORD!"
+	default:
+		return fmt.Sprintf("You placed #%d on the leaderboard!", s.scoreRank)
+	}
+}
+
+// leaderboardRow formats a single entry of the leaderboard, with its score for a shift or its time otherwise
+func leaderboardRow(rank int, entry scores.Entry) string {
+	result := scores.FormatTime(entry.Time)
+	if strings.HasPrefix(entry.Difficulty, shiftLeaderboard) {
+		result = fmt.Sprintf("%d pts", entry.Score)
+	}
+	return fmt.Sprintf("%2d. %-16s %8s  %s", rank, entry.Player, result, entry.Date.Local().Format("2006-01-02"))
+}
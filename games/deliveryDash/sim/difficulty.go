@@ -0,0 +1,70 @@
+package sim
+
+const (
+	DefaultDifficulty = "normal" // Difficulty used when none is picked
+	CustomDifficulty  = "custom" // Name of any difficulty that isn't one of the presets
+)
+
+// Difficulty is how chaotic the city is: how often the walls and the customer move, how quickly the car can, how much
+// time and fuel there is to make the delivery with, how much traffic is in the way, and how many power-ups help out
+type Difficulty struct {
+	WallChance       float64 `json:"wallChance"`       // Chance of each wall toggling whenever the walls shift
+	WallInterval     int     `json:"wallInterval"`     // Ticks between shifts of the walls
+	CustomerInterval int     `json:"customerInterval"` // Ticks between moves of the customer
+	MoveCooldown     int     `json:"moveCooldown"`     // Ticks between moves of the car
+	Deadline         float64 `json:"deadline"`         // Time to deliver in, as a multiple of the shortest route at full speed, or 0 for none
+	Fuel             float64 `json:"fuel"`             // Moves the tank holds, as a multiple of the shortest route, or 0 for an endless tank
+	Traffic          int     `json:"traffic"`          // Number of vehicles driving around the maze
+	CrashesEnd       bool    `json:"crashesEnd"`       // Whether crashing into traffic ends the run, rather than costing time
+	PowerUps         int     `json:"powerUps"`         // Number of power-ups lying around the maze
+}
+
+// difficultyNames are the presets in order, from the calmest city to the most chaotic
+var difficultyNames = []string{"relaxed", "normal", "chaotic", "nightmare"}
+
+// difficulties are the presets by name. Normal is the city Delivery Dash has always had, with none of the deadline,
+// fuel, traffic or power-ups the others add, so that it keeps its leaderboard from before there were difficulties.
+var difficulties = map[string]Difficulty{
+	"relaxed":   {WallChance: 0.25, WallInterval: secondsToTicks(1), CustomerInterval: secondsToTicks(0.5), MoveCooldown: 8, PowerUps: 4},
+	"normal":    {WallChance: 0.75, WallInterval: secondsToTicks(0.25), CustomerInterval: secondsToTicks(0.05), MoveCooldown: 10},
+	"chaotic":   {WallChance: 0.85, WallInterval: secondsToTicks(0.15), CustomerInterval: secondsToTicks(0.05), MoveCooldown: 10, Deadline: 4, Fuel: 3, Traffic: 3, PowerUps: 2},
+	"nightmare": {WallChance: 0.95, WallInterval: secondsToTicks(0.1), CustomerInterval: 1, MoveCooldown: 12, Deadline: 3, Fuel: 2.5, Traffic: 4, CrashesEnd: true, PowerUps: 1},
+}
+
+// Difficulties returns the names of every difficulty preset, from the calmest to the most chaotic
+func Difficulties() []string {
+	return append([]string(nil), difficultyNames...)
+}
+
+// DifficultyPreset returns the difficulty preset with the given name
+func DifficultyPreset(name string) (Difficulty, bool) {
+	d, ok := difficulties[name]
+	return d, ok
+}
+
+// Name returns the name of the preset the difficulty is, or CustomDifficulty if it isn't one of them
+func (d Difficulty) Name() string {
+	for _, name := range difficultyNames {
+		if difficulties[name] == d {
+			return name
+		}
+	}
+	return CustomDifficulty
+}
+
+// clamp keeps every part of the difficulty within what the world can play
+func (d Difficulty) clamp() Difficulty {
+	d.WallChance = min(max(d.WallChance, 0), 1)
+	d.WallInterval = max(d.WallInterval, 1)
+	d.CustomerInterval = max(d.CustomerInterval, 1)
+	d.MoveCooldown = max(d.MoveCooldown, 1)
+	d.Traffic = max(d.Traffic, 0)
+	d.PowerUps = max(d.PowerUps, 0)
+	if d.Deadline > 0 {
+		d.Deadline = max(d.Deadline, 1) // Any less and even the shortest route couldn't make it
+	}
+	if d.Fuel > 0 {
+		d.Fuel = max(d.Fuel, 1)
+	}
+	return d
+}
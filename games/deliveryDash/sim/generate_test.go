@@ -0,0 +1,91 @@
+package sim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// generate builds a maze with a generator from a fresh source of the seed
+func generate(t *testing.T, name string, seed int64, width, height, startX, endX int) [][]bool {
+	t.Helper()
+	g, ok := Generator(name)
+	if !ok {
+		t.Fatalf("expected generator %q to exist", name)
+	}
+	return g.Generate(rand.New(rand.NewSource(seed)), width, height, startX, endX)
+}
+
+func TestGeneratorsConnectEntranceToCustomer(t *testing.T) {
+	sizes := [][2]int{{MazeWidth, MazeHeight}, {21, 16}, {8, 5}, {MinMazeSize, MinMazeSize}, {40, 30}}
+	for _, name := range Generators() {
+		for _, size := range sizes {
+			width, height := size[0], size[1]
+			startX := width / 2
+			for _, endX := range []int{startX, 0, width - 1} {
+				for seed := int64(0); seed < 50; seed++ {
+					maze := generate(t, name, seed, width, height, startX, endX)
+					if len(maze) != height || len(maze[0]) != width {
+						t.Fatalf("%s seed %d: expected a %dx%d maze, got %dx%d", name, seed, width, height, len(maze[0]), len(maze))
+					}
+					if !connected(maze, startX, 0, endX, height-1) {
+						t.Fatalf("%s %dx%d seed %d: expected a route from column %d to column %d", name, width, height, seed, startX, endX)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestGeneratorsArePure(t *testing.T) {
+	for _, name := range Generators() {
+		for seed := int64(0); seed < 20; seed++ {
+			a := generate(t, name, seed, MazeWidth, MazeHeight, MazeWidth/2, 3)
+			b := generate(t, name, seed, MazeWidth, MazeHeight, MazeWidth/2, 3)
+			for y := range a {
+				for x := range a[y] {
+					if a[y][x] != b[y][x] {
+						t.Fatalf("%s seed %d: expected the same maze from the same seed, got a difference at %d,%d", name, seed, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRoomGeneratorsArePerfect(t *testing.T) {
+	// With odd sizes and the entrance and customer on rooms, finishing the maze opens nothing new, so its open cells
+	// must form a tree: every one reachable from the entrance, with one fewer joins between them than there are cells
+	for _, name := range Generators() {
+		if name == DefaultGenerator {
+			continue // The city has loops on purpose
+		}
+		for _, size := range [][2]int{{21, 15}, {9, 7}, {41, 31}} {
+			width, height := size[0], size[1]
+			for seed := int64(0); seed < 50; seed++ {
+				maze := generate(t, name, seed, width, height, 0, width-1)
+
+				cells, joins := 0, 0
+				for y := range maze {
+					for x := range maze[y] {
+						if maze[y][x] {
+							continue
+						}
+						cells++
+						if x+1 < width && !maze[y][x+1] {
+							joins++
+						}
+						if y+1 < height && !maze[y+1][x] {
+							joins++
+						}
+						if !connected(maze, 0, 0, x, y) {
+							t.Fatalf("%s %dx%d seed %d: expected %d,%d to be reachable", name, width, height, seed, x, y)
+						}
+					}
+				}
+				if joins != cells-1 {
+					t.Fatalf("%s %dx%d seed %d: expected %d joins between %d open cells, got %d", name, width, height, seed, cells-1, cells, joins)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package sim
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// DefaultGenerator is the maze generator used when none is picked
+const DefaultGenerator = "city"
+
+// MazeGenerator builds the layout a world starts with, as rows of cells that are true for walls. Generators are
+// pure: every random decision comes from rng, so the same seed always builds the same maze. The maze they return
+// always has a route from the entrance at (startX, 0) to the customer at (endX, height-1).
+type MazeGenerator interface {
+	Generate(rng *rand.Rand, width, height, startX, endX int) [][]bool
+}
+
+var generators = map[string]MazeGenerator{
+	"city":        cityGenerator{},
+	"backtracker": backtrackerGenerator{},
+	"prim":        primGenerator{},
+	"kruskal":     kruskalGenerator{},
+	"wilson":      wilsonGenerator{},
+}
+
+// Generators returns the names of every maze generator, sorted
+func Generators() []string {
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Generator returns the maze generator with the given name
+func Generator(name string) (MazeGenerator, bool) {
+	g, ok := generators[name]
+	return g, ok
+}
+
+// cityGenerator is the chaotic city Delivery Dash has always had: an open grid with a cluster of walls in the middle,
+// two diagonal walls across the top half, and a quarter of the cells walled off at random, with a winding road
+// carved from the entrance to the customer afterwards so that the walls can never block it
+type cityGenerator struct{}
+
+func (cityGenerator) Generate(rng *rand.Rand, width, height, startX, endX int) [][]bool {
+	maze := newMaze(width, height, false)
+	centerX := width / 2
+	centerY := height / 2
+
+	// Add random walls in a 5x5 area around the center
+	for y := max(centerY-2, 0); y <= min(centerY+2, height-1); y++ {
+		for x := max(centerX-2, 0); x <= min(centerX+2, width-1); x++ {
+			if rng.Float32() < 0.6 { // 60% chance of wall
+				maze[y][x] = true
+			}
+		}
+	}
+
+	// Add some random diagonal walls
+	for i := 0; i < diagonalRows(width, height); i++ {
+		if rng.Float32() < 0.7 { // 70% chance of wall
+			maze[i][i] = true
+			maze[i][i+1] = true
+		}
+		if rng.Float32() < 0.7 { // 70% chance of wall
+			maze[i][width-1-i] = true
+			maze[i][width-2-i] = true
+		}
+	}
+
+	// Add some random walls (only 25% of the cells)
+	for i := 0; i < width*height/4; i++ {
+		maze[rng.Intn(height)][rng.Intn(width)] = true
+	}
+
+	// Carve a winding road to the customer, usually heading toward it but sometimes swerving to the side
+	x, y := startX, 0
+	maze[y][x] = false
+	for steps := 0; x != endX || y != height-1; steps++ {
+		switch {
+		case rng.Float32() < 0.4 && steps < width*height:
+			// Swerve, giving up on swerving if the road has gone on for far too long
+			if rng.Intn(2) == 0 && x > 0 {
+				x--
+			} else if x < width-1 {
+				x++
+			}
+		case y < height-1:
+			y++
+		case x < endX:
+			x++
+		default:
+			x--
+		}
+		maze[y][x] = false
+	}
+
+	return maze
+}
+
+// The remaining generators build perfect mazes (exactly one route between any two places) on a grid of rooms at the
+// even cells, knocking down the wall cell between two rooms to join them.
+
+// backtrackerGenerator is a recursive backtracker: a random walk that carves into unvisited rooms, backing up
+// whenever it gets stuck, which makes long twisting corridors
+type backtrackerGenerator struct{}
+
+func (backtrackerGenerator) Generate(rng *rand.Rand, width, height, startX, endX int) [][]bool {
+	rooms := newRoomGrid(width, height)
+	visited := make([]bool, rooms.count())
+
+	start := rooms.index(rooms.nearest(startX, 0))
+	stack := []int{start}
+	visited[start] = true
+
+	for len(stack) > 0 {
+		room := stack[len(stack)-1]
+
+		var unvisited []int
+		for _, next := range rooms.neighbours(room) {
+			if !visited[next] {
+				unvisited = append(unvisited, next)
+			}
+		}
+		if len(unvisited) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		next := unvisited[rng.Intn(len(unvisited))]
+		rooms.join(room, next)
+		visited[next] = true
+		stack = append(stack, next)
+	}
+
+	return rooms.finish(startX, endX)
+}
+
+// primGenerator is randomized Prim's algorithm: the maze grows outward from the entrance by joining a random room on
+// its edge each step, which makes lots of short dead ends
+type primGenerator struct{}
+
+func (primGenerator) Generate(rng *rand.Rand, width, height, startX, endX int) [][]bool {
+	rooms := newRoomGrid(width, height)
+	inMaze := make([]bool, rooms.count())
+
+	type passage struct{ from, to int }
+	var frontier []passage
+	add := func(room int) {
+		inMaze[room] = true
+		for _, next := range rooms.neighbours(room) {
+			if !inMaze[next] {
+				frontier = append(frontier, passage{room, next})
+			}
+		}
+	}
+
+	add(rooms.index(rooms.nearest(startX, 0)))
+	for len(frontier) > 0 {
+		i := rng.Intn(len(frontier))
+		p := frontier[i]
+		frontier[i] = frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+
+		if inMaze[p.to] {
+			continue
+		}
+		rooms.join(p.from, p.to)
+		add(p.to)
+	}
+
+	return rooms.finish(startX, endX)
+}
+
+// kruskalGenerator is randomized Kruskal's algorithm: every wall between two rooms is knocked down in a random order,
+// unless the rooms are already connected, which makes an even, unbiased texture
+type kruskalGenerator struct{}
+
+func (kruskalGenerator) Generate(rng *rand.Rand, width, height, startX, endX int) [][]bool {
+	rooms := newRoomGrid(width, height)
+
+	type passage struct{ from, to int }
+	var passages []passage
+	for room := 0; room < rooms.count(); room++ {
+		for _, next := range rooms.neighbours(room) {
+			if next > room {
+				passages = append(passages, passage{room, next})
+			}
+		}
+	}
+	rng.Shuffle(len(passages), func(i, j int) { passages[i], passages[j] = passages[j], passages[i] })
+
+	// Union-find of which rooms are already connected
+	parent := make([]int, rooms.count())
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(room int) int {
+		if parent[room] != room {
+			parent[room] = find(parent[room])
+		}
+		return parent[room]
+	}
+
+	for _, p := range passages {
+		a, b := find(p.from), find(p.to)
+		if a == b {
+			continue
+		}
+		parent[a] = b
+		rooms.join(p.from, p.to)
+	}
+
+	return rooms.finish(startX, endX)
+}
+
+// wilsonGenerator is Wilson's algorithm: random walks from each room outside the maze, with any loops erased, until
+// they reach the maze, which picks uniformly among every possible perfect maze
+type wilsonGenerator struct{}
+
+func (wilsonGenerator) Generate(rng *rand.Rand, width, height, startX, endX int) [][]bool {
+	rooms := newRoomGrid(width, height)
+	inMaze := make([]bool, rooms.count())
+	inMaze[rooms.index(rooms.nearest(startX, 0))] = true
+	next := make([]int, rooms.count()) // Where the walk last left each room, which erases loops by overwriting them
+
+	for room := 0; room < rooms.count(); room++ {
+		if inMaze[room] {
+			continue
+		}
+
+		// Walk randomly until reaching the maze
+		for current := room; !inMaze[current]; current = next[current] {
+			neighbours := rooms.neighbours(current)
+			next[current] = neighbours[rng.Intn(len(neighbours))]
+		}
+
+		// Join the loop-erased walk to the maze
+		for current := room; !inMaze[current]; current = next[current] {
+			rooms.join(current, next[current])
+			inMaze[current] = true
+		}
+	}
+
+	return rooms.finish(startX, endX)
+}
+
+// roomGrid is a maze of walls with a room at every cell whose coordinates are both even
+type roomGrid struct {
+	maze          [][]bool
+	width, height int // Size of the maze in cells
+	cols, rows    int // Size of the maze in rooms
+}
+
+func newRoomGrid(width, height int) *roomGrid {
+	g := &roomGrid{
+		maze:   newMaze(width, height, true),
+		width:  width,
+		height: height,
+		cols:   (width + 1) / 2,
+		rows:   (height + 1) / 2,
+	}
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x += 2 {
+			g.maze[y][x] = false
+		}
+	}
+
+	return g
+}
+
+func (g *roomGrid) count() int {
+	return g.cols * g.rows
+}
+
+// index returns the room at the given cell
+func (g *roomGrid) index(x, y int) int {
+	return y/2*g.cols + x/2
+}
+
+// cell returns the cell of a room
+func (g *roomGrid) cell(room int) (int, int) {
+	return room % g.cols * 2, room / g.cols * 2
+}
+
+// nearest returns the cell of the room closest to the given cell
+func (g *roomGrid) nearest(x, y int) (int, int) {
+	return min(x/2, g.cols-1) * 2, min(y/2, g.rows-1) * 2
+}
+
+// neighbours returns the rooms next to a room, in a fixed order so that generation only depends on the seed
+func (g *roomGrid) neighbours(room int) []int {
+	col, row := room%g.cols, room/g.cols
+	var rooms []int
+	if row > 0 {
+		rooms = append(rooms, room-g.cols)
+	}
+	if col < g.cols-1 {
+		rooms = append(rooms, room+1)
+	}
+	if row < g.rows-1 {
+		rooms = append(rooms, room+g.cols)
+	}
+	if col > 0 {
+		rooms = append(rooms, room-1)
+	}
+	return rooms
+}
+
+// join knocks down the wall between two neighbouring rooms
+func (g *roomGrid) join(a, b int) {
+	ax, ay := g.cell(a)
+	bx, by := g.cell(b)
+	g.maze[(ay+by)/2][(ax+bx)/2] = false
+}
+
+// finish returns the maze, opening the bottom row below the rooms when it's a row of walls (an even height), and making
+// sure the entrance and customer are joined to the rooms
+func (g *roomGrid) finish(startX, endX int) [][]bool {
+	if g.height%2 == 0 {
+		for x := 0; x < g.width; x += 2 {
+			g.maze[g.height-1][x] = false
+		}
+	}
+
+	ensureRoute(g.maze, startX, endX)
+
+	return g.maze
+}
+
+func newMaze(width, height int, walls bool) [][]bool {
+	maze := make([][]bool, height)
+	for y := range maze {
+		maze[y] = make([]bool, width)
+		for x := range maze[y] {
+			maze[y][x] = walls
+		}
+	}
+	return maze
+}
+
+// ensureRoute opens the entrance and customer cells, then a straight road from the entrance down to the bottom row and
+// along it to the customer, unless that already gave the maze a route between them
+func ensureRoute(maze [][]bool, startX, endX int) {
+	height := len(maze)
+	maze[0][startX] = false
+	maze[height-1][endX] = false
+	if connected(maze, startX, 0, endX, height-1) {
+		return
+	}
+
+	for y := 0; y < height; y++ {
+		maze[y][startX] = false
+	}
+	for x := min(startX, endX); x <= max(startX, endX); x++ {
+		maze[height-1][x] = false
+	}
+}
+
+// connected reports whether there's a route of open cells between two cells of the maze
+func connected(maze [][]bool, fromX, fromY, toX, toY int) bool {
+	height, width := len(maze), len(maze[0])
+	if maze[fromY][fromX] || maze[toY][toX] {
+		return false
+	}
+
+	visited := make([]bool, width*height)
+	queue := []int{fromY*width + fromX}
+	visited[queue[0]] = true
+
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		x, y := cell%width, cell/width
+		if x == toX && y == toY {
+			return true
+		}
+
+		for _, next := range [][2]int{{x, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y}} {
+			nx, ny := next[0], next[1]
+			if nx < 0 || nx >= width || ny < 0 || ny >= height || maze[ny][nx] || visited[ny*width+nx] {
+				continue
+			}
+			visited[ny*width+nx] = true
+			queue = append(queue, ny*width+nx)
+		}
+	}
+
+	return false
+}
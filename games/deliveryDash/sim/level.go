@@ -0,0 +1,246 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// A level is a hand-made city: instead of a generated maze, its map says which cells are streets, which are permanent
+// walls, and which are shifting walls (in regions that each shift as often as the level says), along with the columns
+// the car can start above and the customer moves between below. Levels are stored as JSON, with the map as one string
+// per row, for example:
+//
+//	{
+//	  "name": "Roadworks",
+//	  "par": 20,
+//	  "map": [
+//	    "..aa..",
+//	    "##AA#.",
+//	    "......"
+//	  ],
+//	  "regions": {"a": 0.5},
+//	  "start": [0, 1],
+//	  "customer": [3, 5],
+//	  "hazards": [{"x": 4, "y": 2, "behavior": "patrol"}]
+//	}
+//
+// In the map, '.' is a street that's always open and '#' is a permanent wall. A letter is a shifting wall in the
+// region of that letter, starting open when it's lowercase and closed when it's uppercase. Hazards are traffic that
+// starts in a cell of the level, in place of the difficulty's traffic.
+const (
+	LevelStreet = '.' // Street that's always open
+	LevelWall   = '#' // Permanent wall
+)
+
+// Level is a hand-made city, played instead of a generated one when it's in a world's config
+type Level struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Difficulty  string             `json:"difficulty,omitempty"` // Preset to play the level on, or empty for the default
+	Par         float64            `json:"par,omitempty"`        // Seconds a good delivery takes, or 0 for none
+	Map         []string           `json:"map"`                  // One string per row of the maze, see LevelStreet and LevelWall
+	Regions     map[string]float64 `json:"regions,omitempty"`    // Chance of each region's cells toggling whenever the walls shift, by its lowercase letter
+	Start       Lane               `json:"start"`                // Columns the car can start above
+	Customer    Lane               `json:"customer"`             // Columns the customer moves between below the maze
+	Hazards     []Hazard           `json:"hazards,omitempty"`    // Traffic to start in the maze, instead of the difficulty's
+}
+
+// Lane is the first and last of a run of columns
+type Lane [2]int
+
+// Hazard is a vehicle of traffic that starts in a cell of a level
+type Hazard struct {
+	X        int      `json:"x"`
+	Y        int      `json:"y"`
+	Behavior Behavior `json:"behavior"`
+}
+
+// LoadLevel reads and checks the level file at path
+func LoadLevel(path string) (*Level, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	level, err := ParseLevel(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return level, nil
+}
+
+// ParseLevel decodes and checks a level from its JSON
+func ParseLevel(data []byte) (*Level, error) {
+	var level Level
+	if err := json.Unmarshal(data, &level); err != nil {
+		return nil, fmt.Errorf("not a level file: %w", err)
+	}
+	if err := level.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &level, nil
+}
+
+// Validate checks that the level can be played: a sensibly sized, rectangular map of known cells, lanes inside it,
+// and a route from the start to the customer once every shifting wall is open
+func (l *Level) Validate() error {
+	if l.Name == "" {
+		return fmt.Errorf("the level has no name")
+	}
+	if _, ok := DifficultyPreset(l.Difficulty); l.Difficulty != "" && !ok {
+		return fmt.Errorf("unknown difficulty %q (choose from %s)", l.Difficulty, strings.Join(Difficulties(), ", "))
+	}
+	if l.Par < 0 {
+		return fmt.Errorf("par can't be negative")
+	}
+
+	height := len(l.Map)
+	if height < MinMazeSize || height > MaxMazeSize {
+		return fmt.Errorf("the map must have between %d and %d rows", MinMazeSize, MaxMazeSize)
+	}
+	width := len(l.Map[0])
+	if width < MinMazeSize || width > MaxMazeSize {
+		return fmt.Errorf("the map must have between %d and %d columns", MinMazeSize, MaxMazeSize)
+	}
+
+	for y, row := range l.Map {
+		if len(row) != width {
+			return fmt.Errorf("row %d of the map is %d cells wide, but the first is %d", y+1, len(row), width)
+		}
+		for x, c := range row {
+			_, known := l.Regions[l.region(x, y)]
+			if c != LevelStreet && c != LevelWall && !known {
+				return fmt.Errorf("unknown cell %q at row %d, column %d (use %q, %q, or the letter of a region)", c, y+1, x+1, LevelStreet, LevelWall)
+			}
+		}
+	}
+	for name, chance := range l.Regions {
+		if len(name) != 1 || name[0] < 'a' || name[0] > 'z' {
+			return fmt.Errorf("region %q must be named by a single lowercase letter", name)
+		}
+		if chance < 0 || chance > 1 {
+			return fmt.Errorf("the chance of region %q must be between 0 and 1", name)
+		}
+	}
+
+	for _, lane := range []struct {
+		name string
+		lane Lane
+	}{{"start", l.Start}, {"customer", l.Customer}} {
+		if lane.lane[0] < 0 || lane.lane[1] >= width || lane.lane[0] > lane.lane[1] {
+			return fmt.Errorf("the %s lane must be columns from 0 to %d, first to last", lane.name, width-1)
+		}
+	}
+	if len(l.columns(l.Start, 0)) == 0 || len(l.columns(l.Customer, height-1)) == 0 {
+		return fmt.Errorf("the start and customer lanes need a cell next to them that starts open")
+	}
+	start := l.layout(true)
+	for _, hazard := range l.Hazards {
+		if hazard.X < 0 || hazard.X >= width || hazard.Y < 0 || hazard.Y >= height || start[hazard.Y][hazard.X] {
+			return fmt.Errorf("the hazard at row %d, column %d must start on a cell that's open", hazard.Y+1, hazard.X+1)
+		}
+	}
+	if !l.Solvable() {
+		return fmt.Errorf("there's no way from the start to the customer, even with every shifting wall open")
+	}
+
+	return nil
+}
+
+// Solvable reports whether the car could get from the start to the customer once every shifting wall is open, which
+// the walls can always shift into since they never close off the car's route
+func (l *Level) Solvable() bool {
+	open := l.layout(false)
+	for _, startX := range l.columns(l.Start, 0) {
+		for _, endX := range l.columns(l.Customer, len(l.Map)-1) {
+			if connected(open, startX, 0, endX, len(l.Map)-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Clone returns a copy of the level that can be changed without changing this one
+func (l *Level) Clone() *Level {
+	clone := *l
+	clone.Map = append([]string(nil), l.Map...)
+	clone.Hazards = append([]Hazard(nil), l.Hazards...)
+	clone.Regions = make(map[string]float64, len(l.Regions))
+	for name, chance := range l.Regions {
+		clone.Regions[name] = chance
+	}
+	return &clone
+}
+
+// Size returns the number of cells the level is wide and tall
+func (l *Level) Size() (int, int) {
+	return len(l.Map[0]), len(l.Map)
+}
+
+// region returns the lowercase letter of the region a cell is in, or "" if it doesn't shift
+func (l *Level) region(x, y int) string {
+	c := l.Map[y][x]
+	if c == LevelStreet || c == LevelWall {
+		return ""
+	}
+	return strings.ToLower(string(c))
+}
+
+// layout returns the level's maze as it starts, or with every shifting wall open
+func (l *Level) layout(closed bool) [][]bool {
+	width, height := l.Size()
+	maze := newMaze(width, height, false)
+	for y, row := range l.Map {
+		for x, c := range row {
+			maze[y][x] = c == LevelWall || (closed && c >= 'A' && c <= 'Z')
+		}
+	}
+	return maze
+}
+
+// columns returns the columns of a lane whose cell in a row starts open. The walls never shift while the car waits
+// above the maze, so it could never drive into a cell that starts closed.
+func (l *Level) columns(lane Lane, y int) []int {
+	var columns []int
+	for x := lane[0]; x <= lane[1]; x++ {
+		if c := l.Map[y][x]; c != LevelWall && (c < 'A' || c > 'Z') {
+			columns = append(columns, x)
+		}
+	}
+	return columns
+}
+
+// wallChance returns the chance of a cell toggling whenever the walls shift: its region's chance in a level, or the
+// difficulty's for any cell of a generated city except its permanent walls
+func (w *World) wallChance(x, y int) float64 {
+	if level := w.config.Level; level != nil {
+		return level.Regions[level.region(x, y)]
+	}
+	if onDiagonal(w.width, w.height, x, y) || inCenterCross(w.width, w.height, x, y) {
+		return 0
+	}
+	return w.config.Difficulty.WallChance
+}
+
+// Permanent reports whether a cell of the maze never shifts, so that it's always a wall or always open
+func (w *World) Permanent(x, y int) bool {
+	return w.wallChance(x, y) == 0
+}
+
+// customerLane returns the columns the customer moves between below the maze
+func (w *World) customerLane() Lane {
+	if w.config.Level != nil {
+		return w.config.Level.Customer
+	}
+	return Lane{0, w.width - 1}
+}
+
+// randomCustomerX picks a random place in the customer's lane
+func (w *World) randomCustomerX() int {
+	lane := w.customerLane()
+	return lane[0] + w.rng.Intn(lane[1]-lane[0]+1)
+}
@@ -0,0 +1,325 @@
+package sim
+
+// rerouteBudget is how many cells each side of a detour search may reach before giving up and treating the closed
+// cell as needed, which keeps every wall change cheap in huge mazes. It's larger than the default maze, so the
+// default maze is always checked exactly, and giving up early only ever keeps a wall open, never traps the car.
+const rerouteBudget = 512
+
+// pathfinder answers whether the car can still reach the customer as the walls shift and the customer moves. It keeps
+// a known route from the car to the customer so that most changes are answered without searching the whole maze:
+// closing a cell off the route can't matter, closing a cell on it only needs a detour around that cell, and a
+// customer who moves only needs a way back to the route. Cells are numbered y*width+x, and every buffer is reused,
+// so checking a change never copies the maze.
+type pathfinder struct {
+	width, height int
+	searches      uint32      // Number of searches so far
+	stamp         [2]uint32   // Number of the search each set of buffers is being used for
+	seen          [2][]uint32 // Number of the search that last reached each cell, so nothing needs clearing
+	parent        [2][]int32  // Cell each cell was first reached from
+	queue         [2][]int32
+	route         []int32 // Known route from the car to the customer, in order
+	routeIndex    []int32 // Position of each cell on the known route, or -1 if it isn't on it
+	spare         []int32 // Buffer the next route is built in
+}
+
+func newPathfinder(width, height int) pathfinder {
+	p := pathfinder{
+		width:      width,
+		height:     height,
+		routeIndex: make([]int32, width*height),
+	}
+	for side := range p.seen {
+		p.seen[side] = make([]uint32, width*height)
+		p.parent[side] = make([]int32, width*height)
+	}
+	for i := range p.routeIndex {
+		p.routeIndex[i] = -1
+	}
+
+	return p
+}
+
+// hasRoute reports whether the known route runs between these two cells
+func (p *pathfinder) hasRoute(from, to int32) bool {
+	return len(p.route) > 0 && p.route[0] == from && p.route[len(p.route)-1] == to
+}
+
+// startsAt reports whether the known route starts at a cell, wherever it ends
+func (p *pathfinder) startsAt(from int32) bool {
+	return len(p.route) > 0 && p.route[0] == from
+}
+
+// moveStart moves the start of the known route to a cell next to it, following the car
+func (p *pathfinder) moveStart(to int32) {
+	switch {
+	case len(p.route) == 0:
+	case p.onRoute(to) >= 0:
+		p.setRoute(append(p.spare[:0], p.route[p.onRoute(to):]...))
+	default:
+		p.setRoute(append(append(p.spare[:0], to), p.route...))
+	}
+}
+
+// forget drops the known route, when it might no longer be open
+func (p *pathfinder) forget() {
+	p.setRoute(p.spare[:0])
+}
+
+// onRoute returns the position of a cell on the known route, or -1 if it isn't on it
+func (p *pathfinder) onRoute(cell int32) int {
+	return int(p.routeIndex[cell])
+}
+
+// findRoute searches the whole maze for the shortest route between two cells, making it the known route if there is
+// one
+func (p *pathfinder) findRoute(maze [][]bool, from, to int32) bool {
+	if p.wall(maze, from) || p.wall(maze, to) {
+		return false
+	}
+	if from == to {
+		p.setRoute(append(p.spare[:0], from))
+		return true
+	}
+
+	p.start(0, from)
+	for head := 0; head < len(p.queue[0]); head++ {
+		found := p.expand(maze, 0, p.queue[0][head], toCell, int(to))
+		if found < 0 {
+			continue
+		}
+
+		// Walk back from the customer to the car, then turn the route around
+		route := p.spare[:0]
+		for cell := found; cell != from; cell = p.parent[0][cell] {
+			route = append(route, cell)
+		}
+		route = append(route, from)
+		reverse(route)
+		p.setRoute(route)
+		return true
+	}
+
+	return false
+}
+
+// extend searches from a new place for the customer back to the known route, making the route to the new place the
+// known route if there is one
+func (p *pathfinder) extend(maze [][]bool, to int32) bool {
+	if p.wall(maze, to) {
+		return false
+	}
+	if i := p.onRoute(to); i >= 0 {
+		p.setRoute(append(p.spare[:0], p.route[:i+1]...))
+		return true
+	}
+
+	p.start(0, to)
+	for head := 0; head < len(p.queue[0]); head++ {
+		found := p.expand(maze, 0, p.queue[0][head], toRouteAfter, -1)
+		if found < 0 {
+			continue
+		}
+
+		// Follow the route to where the search reached it, then the search's steps back to the customer
+		route := append(p.spare[:0], p.route[:p.onRoute(found)+1]...)
+		for cell := p.parent[0][found]; cell != to; cell = p.parent[0][cell] {
+			route = append(route, cell)
+		}
+		p.setRoute(append(route, to))
+		return true
+	}
+
+	return false
+}
+
+// reroute looks for a detour around the cell at position k of the known route, which has just been closed, making
+// the route with the detour the known route if there is one. It searches forward from the cell before the closed one
+// and backward from the cell after it at the same time, stopping as soon as either side finds its way around or runs
+// out of places to go (or past rerouteBudget), so it's quick whenever there's a short detour or either side of the
+// closed cell is small.
+func (p *pathfinder) reroute(maze [][]bool, k int) bool {
+	if k <= 0 || k >= len(p.route)-1 {
+		return false // The car or the customer itself
+	}
+
+	p.start(0, p.route[k-1])
+	p.start(1, p.route[k+1])
+
+	for head := 0; head < len(p.queue[0]) && head < len(p.queue[1]) && head < rerouteBudget; head++ {
+		if found := p.expand(maze, 0, p.queue[0][head], toRouteAfter, k); found >= 0 {
+			// The forward search's steps lead back from where the detour rejoins the route to where it leaves it
+			leave := p.parent[0][found]
+			for p.onRoute(leave) < 0 {
+				leave = p.parent[0][leave]
+			}
+
+			route := append(p.spare[:0], p.route[:p.onRoute(leave)+1]...)
+			detour := len(route)
+			for cell := p.parent[0][found]; cell != leave; cell = p.parent[0][cell] {
+				route = append(route, cell)
+			}
+			reverse(route[detour:])
+			p.setRoute(append(route, p.route[p.onRoute(found):]...))
+			return true
+		}
+
+		if found := p.expand(maze, 1, p.queue[1][head], toRouteBefore, k); found >= 0 {
+			// The backward search's steps lead forward from where the detour leaves the route to where it rejoins it
+			route := append(p.spare[:0], p.route[:p.onRoute(found)+1]...)
+			cell := p.parent[1][found]
+			for ; p.onRoute(cell) < 0; cell = p.parent[1][cell] {
+				route = append(route, cell)
+			}
+			p.setRoute(append(route, p.route[p.onRoute(cell):]...))
+			return true
+		}
+	}
+
+	return false
+}
+
+// flood searches from a cell everywhere that can be reached from it, for reached to answer
+func (p *pathfinder) flood(maze [][]bool, from int32) {
+	p.start(0, from)
+	for head := 0; head < len(p.queue[0]); head++ {
+		p.expand(maze, 0, p.queue[0][head], toCell, -1)
+	}
+}
+
+// reached reports whether the last flood reached an open cell
+func (p *pathfinder) reached(maze [][]bool, cell int32) bool {
+	return p.seen[0][cell] == p.stamp[0] && !p.wall(maze, cell)
+}
+
+// start begins a new search from a cell, using one of the two sets of search buffers
+func (p *pathfinder) start(side, from int32) {
+	p.searches++
+	p.stamp[side] = p.searches
+	p.seen[side][from] = p.searches
+	p.queue[side] = append(p.queue[side][:0], from)
+}
+
+// What a search is looking for
+const (
+	toCell        = iota // A particular cell
+	toRouteAfter         // Any cell of the known route after a position
+	toRouteBefore        // Any cell of the known route before a position
+)
+
+// expand queues the open neighbours of a cell that the search hasn't reached yet, returning the first one that's
+// what the search is looking for, or -1
+func (p *pathfinder) expand(maze [][]bool, side, cell int32, target, value int) int32 {
+	x, y := int(cell)%p.width, int(cell)/p.width
+	if y > 0 && !maze[y-1][x] {
+		if found := p.reach(side, cell, cell-int32(p.width), target, value); found >= 0 {
+			return found
+		}
+	}
+	if x < p.width-1 && !maze[y][x+1] {
+		if found := p.reach(side, cell, cell+1, target, value); found >= 0 {
+			return found
+		}
+	}
+	if y < p.height-1 && !maze[y+1][x] {
+		if found := p.reach(side, cell, cell+int32(p.width), target, value); found >= 0 {
+			return found
+		}
+	}
+	if x > 0 && !maze[y][x-1] {
+		if found := p.reach(side, cell, cell-1, target, value); found >= 0 {
+			return found
+		}
+	}
+
+	return -1
+}
+
+// reach queues an open cell next to one the search has already reached, unless the search has been there too,
+// returning it if it's what the search is looking for, or -1
+func (p *pathfinder) reach(side, from, cell int32, target, value int) int32 {
+	if p.seen[side][cell] == p.stamp[side] {
+		return -1
+	}
+	p.seen[side][cell] = p.stamp[side]
+	p.parent[side][cell] = from
+	p.queue[side] = append(p.queue[side], cell)
+
+	switch i := int(p.routeIndex[cell]); target {
+	case toCell:
+		if int(cell) == value {
+			return cell
+		}
+	case toRouteAfter:
+		if i > value {
+			return cell
+		}
+	case toRouteBefore:
+		if i >= 0 && i < value {
+			return cell
+		}
+	}
+
+	return -1
+}
+
+// setRoute makes the given cells the known route, keeping the old route's buffer as the spare
+func (p *pathfinder) setRoute(route []int32) {
+	for _, cell := range p.route {
+		p.routeIndex[cell] = -1
+	}
+	for i, cell := range route {
+		p.routeIndex[cell] = int32(i)
+	}
+
+	p.route, p.spare = route, p.route[:0]
+}
+
+func (p *pathfinder) wall(maze [][]bool, cell int32) bool {
+	return maze[int(cell)/p.width][int(cell)%p.width]
+}
+
+func reverse(cells []int32) {
+	for i, j := 0, len(cells)-1; i < j; i, j = i+1, j-1 {
+		cells[i], cells[j] = cells[j], cells[i]
+	}
+}
+
+// ring is the eight cells around a cell in order, so that each is next to the ones before and after it
+var ring = [8][2]int{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
+
+// joinedAround reports whether the open cells beside a cell (above, below, left and right of it) are all joined to
+// each other through the eight cells around it, in which case closing the cell can't cut anything off
+func (p *pathfinder) joinedAround(maze [][]bool, x, y int) bool {
+	open := func(i int) bool {
+		nx, ny := x+ring[i%8][0], y+ring[i%8][1]
+		return nx >= 0 && nx < p.width && ny >= 0 && ny < p.height && !maze[ny][nx]
+	}
+
+	// Start just after a closed cell of the ring, then count the separate runs of open cells holding a side neighbour
+	start := -1
+	for i := 0; i < 8; i++ {
+		if !open(i) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return true // Every cell around it is open
+	}
+
+	groups := 0
+	inRun, runHasSide := false, false
+	for i := start + 1; i <= start+8; i++ {
+		if open(i) {
+			inRun = true
+			runHasSide = runHasSide || i%2 == 0 // Even ring cells are directly above, beside or below
+			continue
+		}
+		if inRun && runHasSide {
+			groups++
+		}
+		inRun, runHasSide = false, false
+	}
+
+	return groups <= 1
+}
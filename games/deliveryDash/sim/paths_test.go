@@ -0,0 +1,227 @@
+package sim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomMaze returns a maze with about a third of its cells walled off at random, which leaves plenty of loops, dead
+// ends and cut off cells for the pathfinder to get wrong
+func randomMaze(rng *rand.Rand, width, height int) [][]bool {
+	maze := newMaze(width, height, false)
+	for y := range maze {
+		for x := range maze[y] {
+			maze[y][x] = rng.Float32() < 0.35
+		}
+	}
+	return maze
+}
+
+// distance returns the fewest steps between two cells found by a plain search of the whole maze, or -1 if there's no
+// route between them
+func distance(maze [][]bool, fromX, fromY, toX, toY int) int {
+	height, width := len(maze), len(maze[0])
+	if maze[fromY][fromX] || maze[toY][toX] {
+		return -1
+	}
+
+	steps := make([]int, width*height)
+	for i := range steps {
+		steps[i] = -1
+	}
+	steps[fromY*width+fromX] = 0
+	queue := []int{fromY*width + fromX}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		x, y := cell%width, cell/width
+		for _, next := range [][2]int{{x, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y}} {
+			nx, ny := next[0], next[1]
+			if nx < 0 || nx >= width || ny < 0 || ny >= height || maze[ny][nx] || steps[ny*width+nx] >= 0 {
+				continue
+			}
+			steps[ny*width+nx] = steps[cell] + 1
+			queue = append(queue, ny*width+nx)
+		}
+	}
+
+	return steps[toY*width+toX]
+}
+
+// checkRoute fails the test unless the known route is a run of open, neighbouring cells between two cells, with every
+// cell's position on it kept up to date
+func checkRoute(t *testing.T, p *pathfinder, maze [][]bool, from, to int32) {
+	t.Helper()
+	if !p.hasRoute(from, to) {
+		t.Fatalf("expected a route from %d to %d, got %v", from, to, p.route)
+	}
+	for i, cell := range p.route {
+		if p.wall(maze, cell) {
+			t.Fatalf("expected the route to stay open, got a wall at %d", cell)
+		}
+		if p.onRoute(cell) != i {
+			t.Fatalf("expected %d at position %d of the route, got %d", cell, i, p.onRoute(cell))
+		}
+		if i == 0 {
+			continue
+		}
+		dx, dy := int(cell)%p.width-int(p.route[i-1])%p.width, int(cell)/p.width-int(p.route[i-1])/p.width
+		if dx*dx+dy*dy != 1 {
+			t.Fatalf("expected the route to move one cell at a time, got %d after %d", cell, p.route[i-1])
+		}
+	}
+
+	onRoute := 0
+	for _, i := range p.routeIndex {
+		if i >= 0 {
+			onRoute++
+		}
+	}
+	if onRoute != len(p.route) {
+		t.Fatalf("expected %d cells marked on the route, got %d", len(p.route), onRoute)
+	}
+}
+
+// The mazes below fit within rerouteBudget, so every answer must match a search of the whole maze exactly
+const testWidth, testHeight = 20, 15
+
+func TestFindRouteMatchesSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	p := newPathfinder(testWidth, testHeight)
+	for i := 0; i < 2000; i++ {
+		maze := randomMaze(rng, testWidth, testHeight)
+		fromX, fromY, toX, toY := rng.Intn(testWidth), rng.Intn(testHeight), rng.Intn(testWidth), rng.Intn(testHeight)
+		from, to := int32(fromY*testWidth+fromX), int32(toY*testWidth+toX)
+
+		want := distance(maze, fromX, fromY, toX, toY)
+		if found := p.findRoute(maze, from, to); found != (want >= 0) {
+			t.Fatalf("maze %d: expected a route to be found to be %v, got %v", i, want >= 0, found)
+		}
+		if want < 0 {
+			continue
+		}
+		checkRoute(t, &p, maze, from, to)
+		if len(p.route)-1 != want {
+			t.Fatalf("maze %d: expected the shortest route of %d steps, got %d", i, want, len(p.route)-1)
+		}
+	}
+}
+
+func TestRerouteMatchesSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	p := newPathfinder(testWidth, testHeight)
+	checked := 0
+	for i := 0; i < 5000; i++ {
+		maze := randomMaze(rng, testWidth, testHeight)
+		fromX, toX := rng.Intn(testWidth), rng.Intn(testWidth)
+		from, to := int32(fromX), int32((testHeight-1)*testWidth+toX)
+		if !p.findRoute(maze, from, to) || len(p.route) < 3 {
+			continue
+		}
+
+		// Close cells on the route one at a time, as the walls would, until there's no way around one
+		for len(p.route) >= 3 {
+			k := 1 + rng.Intn(len(p.route)-2)
+			cell := p.route[k]
+			maze[int(cell)/testWidth][int(cell)%testWidth] = true
+
+			want := connected(maze, fromX, 0, toX, testHeight-1)
+			if found := p.reroute(maze, k); found != want {
+				t.Fatalf("maze %d: expected a detour to be found to be %v, got %v", i, want, found)
+			}
+			checked++
+			if !want {
+				break
+			}
+			checkRoute(t, &p, maze, from, to)
+		}
+	}
+	if checked == 0 {
+		t.Fatalf("expected some routes to reroute")
+	}
+}
+
+func TestExtendMatchesSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	p := newPathfinder(testWidth, testHeight)
+	for i := 0; i < 2000; i++ {
+		maze := randomMaze(rng, testWidth, testHeight)
+		fromX, toX := rng.Intn(testWidth), rng.Intn(testWidth)
+		from := int32(fromX)
+		if !p.findRoute(maze, from, int32((testHeight-1)*testWidth+toX)) {
+			continue
+		}
+
+		// Move the customer along the bottom row, following them from the last route each time
+		for j := 0; j < 5; j++ {
+			toX = rng.Intn(testWidth)
+			to := int32((testHeight-1)*testWidth + toX)
+
+			want := connected(maze, fromX, 0, toX, testHeight-1)
+			if found := p.extend(maze, to); found != want {
+				t.Fatalf("maze %d: expected a way to the customer to be found to be %v, got %v", i, want, found)
+			}
+			if !want {
+				break
+			}
+			checkRoute(t, &p, maze, from, to)
+		}
+	}
+}
+
+func TestJoinedAroundMatchesSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	p := newPathfinder(testWidth, testHeight)
+	joined := 0
+	for i := 0; i < 500; i++ {
+		maze := randomMaze(rng, testWidth, testHeight)
+		for y := range maze {
+			for x := range maze[y] {
+				if maze[y][x] || !p.joinedAround(maze, x, y) {
+					continue
+				}
+				joined++
+
+				// Closing the cell must leave every open cell beside it joined to the others
+				var sides [][2]int
+				for _, side := range [][2]int{{x, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y}} {
+					if side[0] >= 0 && side[0] < testWidth && side[1] >= 0 && side[1] < testHeight && !maze[side[1]][side[0]] {
+						sides = append(sides, side)
+					}
+				}
+				maze[y][x] = true
+				for _, side := range sides[min(1, len(sides)):] {
+					if !connected(maze, sides[0][0], sides[0][1], side[0], side[1]) {
+						t.Fatalf("maze %d: expected closing %d,%d to leave the cells beside it joined", i, x, y)
+					}
+				}
+				maze[y][x] = false
+			}
+		}
+	}
+	if joined == 0 {
+		t.Fatalf("expected some cells to be joined around")
+	}
+}
+
+func BenchmarkStep(b *testing.B) {
+	for _, name := range Generators() {
+		for _, packages := range []int{0, 4} {
+			title := name
+			if packages > 0 {
+				title += "/shift"
+			}
+			b.Run(title, func(b *testing.B) {
+				w := New(1, Config{Generator: name, Width: 200, Height: 200, Packages: packages})
+				w.car.CellX, w.car.CellY = w.startX, 0 // Drive into the maze, so every wall change is checked against a route
+				w.hasStarted = true
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					w.Step(Input{})
+				}
+			})
+		}
+	}
+}
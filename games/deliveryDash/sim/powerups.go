@@ -0,0 +1,125 @@
+package sim
+
+const (
+	freezeDuration = 5 * TicksPerSecond // Ticks the walls stay still after using Freeze
+	turboDuration  = 5 * TicksPerSecond // Ticks the car moves faster after using Turbo
+	radarDuration  = 3 * TicksPerSecond // Ticks the customer's next places are shown after using Radar
+	radarLookahead = 6                  // Number of the customer's next places Radar shows
+)
+
+// PowerUp is something the car can pick up and carry until it's used
+type PowerUp int
+
+const (
+	NoPowerUp PowerUp = iota
+	Freeze            // Stops the walls shifting for a while
+	Bulldozer         // Drives through the next shifting wall the car runs into
+	Turbo             // Halves the cooldown between moves for a while
+	Radar             // Shows where the customer might go next, for a while
+)
+
+// powerUpKinds are the power-ups that can be found in the maze
+var powerUpKinds = []PowerUp{Freeze, Bulldozer, Turbo, Radar} // Radar last, see spawnPowerUp
+
+// Pickup is a power-up lying in a cell of the maze
+type Pickup struct {
+	CellX, CellY int
+	PowerUp      PowerUp
+}
+
+// spawnPowerUp places a random power-up on a random open cell of the maze that doesn't already have a car or power-up
+// in it, giving up if it can't find one
+func (w *World) spawnPowerUp() {
+	for try := 0; try < spawnTries; try++ {
+		x, y := w.rng.Intn(w.width), w.rng.Intn(w.height)
+		if w.maze[y][x] || w.pickupAt(x, y) >= 0 || w.trafficAt(x, y) >= 0 || (x == w.car.CellX && y == w.car.CellY) {
+			continue
+		}
+
+		// Radar only tracks the single customer of a single delivery, so it's left out of shifts
+		kinds := powerUpKinds
+		if w.config.Packages > 0 {
+			kinds = kinds[:len(kinds)-1]
+		}
+		w.pickups = append(w.pickups, Pickup{CellX: x, CellY: y, PowerUp: kinds[w.rng.Intn(len(kinds))]})
+		return
+	}
+}
+
+// collect picks up the power-up in the car's cell, unless the car is already carrying one, and puts another
+// somewhere else in the maze
+func (w *World) collect() {
+	i := w.pickupAt(w.car.CellX, w.car.CellY)
+	if i < 0 || w.carrying != NoPowerUp {
+		return
+	}
+
+	w.carrying = w.pickups[i].PowerUp
+	w.pickups = append(w.pickups[:i], w.pickups[i+1:]...)
+	w.spawnPowerUp()
+}
+
+// usePowerUp uses the power-up the car is carrying
+func (w *World) usePowerUp() {
+	switch w.carrying {
+	case Freeze:
+		w.frozen = freezeDuration
+	case Bulldozer:
+		w.bulldozing = true
+	case Turbo:
+		w.turbo = turboDuration
+	case Radar:
+		w.radar = radarDuration
+		w.planCustomer()
+	}
+	w.carrying = NoPowerUp
+}
+
+// tickPowerUps counts down the power-ups in use, and keeps Radar's view of the customer's next places filled
+func (w *World) tickPowerUps() {
+	w.frozen = max(w.frozen-1, 0)
+	w.turbo = max(w.turbo-1, 0)
+	w.radar = max(w.radar-1, 0)
+	w.planCustomer()
+}
+
+// planCustomer decides where the customer will try to go ahead of time while Radar is showing it, which only changes
+// the order random decisions are made in when Radar is used
+func (w *World) planCustomer() {
+	for w.radar > 0 && len(w.customerPlan) < radarLookahead {
+		w.customerPlan = append(w.customerPlan, w.randomCustomerX())
+	}
+}
+
+// nextCustomerX returns the next place in their lane the customer will try to go
+func (w *World) nextCustomerX() int {
+	if len(w.customerPlan) == 0 {
+		return w.randomCustomerX()
+	}
+
+	x := w.customerPlan[0]
+	w.customerPlan = w.customerPlan[1:]
+	return x
+}
+
+// bulldoze knocks down the wall the car is facing, when it's carrying out a Bulldozer, so that it can drive through.
+// Permanent walls are left standing, since nothing would ever put them back.
+func (w *World) bulldoze(dir Direction) {
+	x, y := ahead(w.car, dir)
+	if !w.bulldozing || w.car.CellY < 0 || !w.inside(x, y) || !w.maze[y][x] || w.Permanent(x, y) {
+		return
+	}
+
+	w.maze[y][x] = false // Opening a cell never cuts anything off, so the known route stays good
+	w.bulldozing = false
+}
+
+// pickupAt returns the index of the power-up in a cell, or -1 if there isn't one
+func (w *World) pickupAt(x, y int) int {
+	for i, p := range w.pickups {
+		if p.CellX == x && p.CellY == y {
+			return i
+		}
+	}
+	return -1
+}
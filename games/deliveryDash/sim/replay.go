@@ -0,0 +1,233 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A replay file starts with a magic string and format version, followed by the game version, the seed, the world's
+// config as JSON, and then every tick's input stored as runs of (input, number of ticks) because most ticks repeat
+// the previous input.
+const (
+	replayMagic   = "DDRP" // Marks the start of a Delivery Dash replay file
+	replayVersion = 2      // Bumped whenever the layout of the replay file changes
+)
+
+// The most a replay file can hold, so that a corrupt or hostile file can't make reading it use up all the memory
+const (
+	maxReplayVersion = 64                            // Bytes of the game version
+	maxReplayConfig  = 1 << 20                       // Bytes of the config as JSON, plenty for the biggest level
+	maxReplayTicks   = 24 * 60 * 60 * TicksPerSecond // Ticks of input, a whole day of play
+)
+
+// Replay is everything needed to play a run back exactly: the seed and config the world was created with, and the
+// input of every tick it was stepped
+type Replay struct {
+	GameVersion string  // Version of the game the replay was recorded with, since gameplay changes break replays
+	Seed        int64   // Seed the world was created with
+	Config      Config  // Config the world was created with
+	Inputs      []Input // Input of every tick, in order
+}
+
+// Record appends the input of one tick to the replay
+func (r *Replay) Record(in Input) {
+	r.Inputs = append(r.Inputs, in)
+}
+
+// Save writes the replay to a file at path
+func (r *Replay) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Write encodes the replay in its compact file format
+func (r *Replay) Write(w io.Writer) error {
+	if len(r.GameVersion) > maxReplayVersion {
+		return fmt.Errorf("the game version can't be longer than %d bytes", maxReplayVersion)
+	}
+	if len(r.Inputs) > maxReplayTicks {
+		return fmt.Errorf("a replay can't be longer than %d ticks", maxReplayTicks)
+	}
+	config, err := json.Marshal(r.Config)
+	if err != nil {
+		return err
+	}
+	if len(config) > maxReplayConfig {
+		return fmt.Errorf("the config can't be larger than %d bytes", maxReplayConfig)
+	}
+
+	buf := bufio.NewWriter(w)
+
+	buf.WriteString(replayMagic)
+	buf.WriteByte(replayVersion)
+	writeUvarint(buf, uint64(len(r.GameVersion)))
+	buf.WriteString(r.GameVersion)
+	writeVarint(buf, r.Seed)
+	writeUvarint(buf, uint64(len(config)))
+	buf.Write(config)
+
+	// Store the inputs as runs of the same input
+	for i := 0; i < len(r.Inputs); {
+		run := 1
+		for i+run < len(r.Inputs) && r.Inputs[i+run] == r.Inputs[i] {
+			run++
+		}
+
+		buf.WriteByte(r.Inputs[i].bits())
+		writeUvarint(buf, uint64(run))
+		i += run
+	}
+
+	return buf.Flush()
+}
+
+// LoadReplay reads a replay file from path
+func LoadReplay(path string) (*Replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadReplay(file)
+}
+
+// ReadReplay decodes a replay written by Write
+func ReadReplay(r io.Reader) (*Replay, error) {
+	buf := bufio.NewReader(r)
+
+	header := make([]byte, len(replayMagic)+1)
+	if _, err := io.ReadFull(buf, header); err != nil || string(header[:len(replayMagic)]) != replayMagic {
+		return nil, errors.New("not a Delivery Dash replay file")
+	}
+	if header[len(replayMagic)] != replayVersion {
+		return nil, fmt.Errorf("unsupported replay format version %d", header[len(replayMagic)])
+	}
+
+	versionLength, err := binary.ReadUvarint(buf)
+	if err != nil {
+		return nil, corruptReplay(err)
+	}
+	if versionLength > maxReplayVersion {
+		return nil, corruptReplay(fmt.Errorf("game version of %d bytes is longer than %d", versionLength, maxReplayVersion))
+	}
+	version := make([]byte, versionLength)
+	if _, err := io.ReadFull(buf, version); err != nil {
+		return nil, corruptReplay(err)
+	}
+
+	seed, err := binary.ReadVarint(buf)
+	if err != nil {
+		return nil, corruptReplay(err)
+	}
+
+	configLength, err := binary.ReadUvarint(buf)
+	if err != nil {
+		return nil, corruptReplay(err)
+	}
+	if configLength > maxReplayConfig {
+		return nil, corruptReplay(fmt.Errorf("config of %d bytes is larger than %d", configLength, maxReplayConfig))
+	}
+	config := make([]byte, configLength)
+	if _, err := io.ReadFull(buf, config); err != nil {
+		return nil, corruptReplay(err)
+	}
+
+	replay := &Replay{
+		GameVersion: string(version),
+		Seed:        seed,
+	}
+	if err := json.Unmarshal(config, &replay.Config); err != nil {
+		return nil, corruptReplay(err)
+	}
+
+	for {
+		bits, err := buf.ReadByte()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, corruptReplay(err)
+		}
+
+		run, err := binary.ReadUvarint(buf)
+		if err != nil {
+			return nil, corruptReplay(err)
+		}
+
+		if run > uint64(maxReplayTicks-len(replay.Inputs)) {
+			return nil, corruptReplay(fmt.Errorf("inputs run past %d ticks", maxReplayTicks))
+		}
+
+		in := inputFromBits(bits)
+		for i := uint64(0); i < run; i++ {
+			replay.Inputs = append(replay.Inputs, in)
+		}
+	}
+
+	return replay, nil
+}
+
+func corruptReplay(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("corrupt replay file: %w", err)
+}
+
+func writeUvarint(w *bufio.Writer, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func writeVarint(w *bufio.Writer, v int64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutVarint(buf[:], v)])
+}
+
+// useBit is the bit of the use button in a packed input, after the four directions
+const useBit = 4
+
+// bits packs the input into a single byte, one bit per direction and one for the use button
+func (in Input) bits() byte {
+	var b byte
+	if in.Up {
+		b |= 1 << Up
+	}
+	if in.Right {
+		b |= 1 << Right
+	}
+	if in.Down {
+		b |= 1 << Down
+	}
+	if in.Left {
+		b |= 1 << Left
+	}
+	if in.Use {
+		b |= 1 << useBit
+	}
+	return b
+}
+
+func inputFromBits(b byte) Input {
+	return Input{
+		Up:    b&(1<<Up) != 0,
+		Right: b&(1<<Right) != 0,
+		Down:  b&(1<<Down) != 0,
+		Left:  b&(1<<Left) != 0,
+		Use:   b&(1<<useBit) != 0,
+	}
+}
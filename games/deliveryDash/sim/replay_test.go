@@ -0,0 +1,58 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// replayHeader returns the start of a replay file, up to the game version's length
+func replayHeader(versionLength uint64) []byte {
+	header := append([]byte(replayMagic), replayVersion)
+	return binary.AppendUvarint(header, versionLength)
+}
+
+func TestReadReplayRejectsHugeLengths(t *testing.T) {
+	config := []byte("{}")
+
+	withConfig := func(configLength uint64) []byte {
+		data := append(replayHeader(5), "1.0.0"...)
+		data = binary.AppendVarint(data, 1)
+		return binary.AppendUvarint(data, configLength)
+	}
+	withRuns := func(runs ...uint64) []byte {
+		data := append(withConfig(uint64(len(config))), config...)
+		for _, run := range runs {
+			data = binary.AppendUvarint(append(data, Input{Down: true}.bits()), run)
+		}
+		return data
+	}
+
+	for name, data := range map[string][]byte{
+		"version": replayHeader(1 << 62),
+		"config":  withConfig(1 << 62),
+		"run":     withRuns(1 << 62),
+		"runs":    withRuns(maxReplayTicks, 1),
+	} {
+		_, err := ReadReplay(bytes.NewReader(data))
+		if err == nil || !strings.HasPrefix(err.Error(), "corrupt replay file") {
+			t.Errorf("%s: expected a corrupt replay file, got %v", name, err)
+		}
+	}
+
+	if replay, err := ReadReplay(bytes.NewReader(withRuns(maxReplayTicks))); err != nil || len(replay.Inputs) != maxReplayTicks {
+		t.Errorf("expected a replay of the longest length to read, got %v", err)
+	}
+}
+
+func TestWriteReplayRejectsWhatCantBeRead(t *testing.T) {
+	for name, replay := range map[string]*Replay{
+		"version": {GameVersion: strings.Repeat("1", maxReplayVersion+1)},
+		"ticks":   {Inputs: make([]Input, maxReplayTicks+1)},
+	} {
+		if err := replay.Write(&bytes.Buffer{}); err == nil {
+			t.Errorf("%s: expected an error writing the replay", name)
+		}
+	}
+}
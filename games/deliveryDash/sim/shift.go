@@ -0,0 +1,421 @@
+package sim
+
+// A shift is a run with several packages instead of a single delivery: the packages lie around the maze, and each has
+// its own customer moving along one of the maze's edges. The car picks packages up by driving over them (carrying a
+// few at once) and delivers each by driving out of the maze toward its customer, and the shift is done once every
+// package is delivered.
+//
+// The car has to be able to reach every target of a shift (each package waiting to be picked up, and the doorstep of
+// each customer waiting for one), so a known route is kept from each target back to the car, the same way as for the
+// single customer: closing a cell that no route uses can't cut anything off, and closing one that a route does use
+// only needs a detour around it.
+
+const (
+	DefaultCapacity = 2    // Packages the car can carry at once during a shift, unless configured otherwise
+	MaxPackages     = 20   // Most packages a shift can have
+	deliveryPoints  = 1000 // Points for each package delivered
+	secondPenalty   = 10   // Points lost for every second the shift takes
+	movePenalty     = 1    // Points lost for every move
+)
+
+// PackageState is where a package is during a shift
+type PackageState int
+
+const (
+	Waiting   PackageState = iota // Lying in the maze, waiting to be picked up
+	Carried                       // In the car
+	Delivered                     // Handed over to its customer
+)
+
+// Package is one delivery of a shift
+type Package struct {
+	CellX, CellY int // Cell the package lies in until it's picked up
+	State        PackageState
+	Edge         Direction // Edge of the maze the package's customer moves along (Up for the top edge, and so on)
+	Offset       int       // How far along its edge the customer is, from the top or left
+}
+
+// edges are handed out to the customers in turn, so that they're spread around the maze
+var edges = []Direction{Down, Right, Left, Up}
+
+// Customer returns the cell just outside the maze where the package's customer is
+func (p Package) Customer(width, height int) (int, int) {
+	switch p.Edge {
+	case Up:
+		return p.Offset, -1
+	case Right:
+		return width, p.Offset
+	case Left:
+		return -1, p.Offset
+	default:
+		return p.Offset, height
+	}
+}
+
+// doorstep returns the cell inside the maze next to the package's customer, which the car delivers from
+func (p Package) doorstep(width, height int) (int, int) {
+	x, y := p.Customer(width, height)
+	return min(max(x, 0), width-1), min(max(y, 0), height-1)
+}
+
+// edgeLength returns the number of places a customer can be along an edge
+func (w *World) edgeLength(edge Direction) int {
+	if edge == Left || edge == Right {
+		return w.height
+	}
+	return w.width
+}
+
+// spawnPackages places the shift's packages on random open cells away from the entrance, and their customers at
+// random places along the edges, all where the car can get to from the entrance. A package is left out when there's
+// nowhere the car can get to for it or its customer, and a shift left without any is played as a single delivery.
+func (w *World) spawnPackages() {
+	w.paths.flood(w.maze, w.cell(w.startX, 0))
+	for i := 0; i < w.config.Packages; i++ {
+		p := Package{Edge: edges[i%len(edges)]}
+		reachable := false
+		for try := 0; try < spawnTries && !reachable; try++ {
+			p.Offset = w.customerOffset(p.Edge)
+			reachable = w.paths.reached(w.maze, w.cell(p.doorstep(w.width, w.height)))
+		}
+		if !reachable {
+			continue
+		}
+
+		for try := 0; try < spawnTries; try++ {
+			x, y := w.rng.Intn(w.width), 1+w.rng.Intn(w.height-1)
+			if !w.paths.reached(w.maze, w.cell(x, y)) || w.packageAt(x, y) >= 0 {
+				continue
+			}
+
+			p.CellX, p.CellY = x, y
+			w.packages = append(w.packages, p)
+			break
+		}
+	}
+
+	w.targetPaths = newPathfinder(w.width, w.height)
+	w.targetRoutes = make([][]int32, 2*len(w.packages))
+	w.routeShortest = make([]int, 2*len(w.packages))
+	w.routeUses = make([]uint8, w.width*w.height)
+}
+
+// customerOffset picks a random place along an edge for a customer, other than the entrance
+func (w *World) customerOffset(edge Direction) int {
+	offset := w.rng.Intn(w.edgeLength(edge))
+	if edge == Up && offset == w.startX {
+		offset = (offset + 1) % w.width
+	}
+	return offset
+}
+
+// moveCustomers moves every customer still waiting for a package to a random place along their edge, as long as the
+// car can still reach them
+func (w *World) moveCustomers() {
+	for i := range w.packages {
+		p := &w.packages[i]
+		if p.State == Delivered {
+			continue
+		}
+
+		old := p.Offset
+		p.Offset = w.customerOffset(p.Edge)
+		x, y := p.doorstep(w.width, w.height)
+		if w.maze[y][x] || w.goalPackage() == i && w.wouldTrapPlayer(x, y) || !w.followCustomer(2*i+1) {
+			p.Offset = old
+		}
+	}
+}
+
+// strands reports whether closing the cell at (x, y) cut the car off from any of a shift's targets. wouldTrapPlayer
+// only looks after the one the car is heading for, and a shift's car has to reach all of them in turn. Anything goes
+// while the car waits at the entrance, like the rest of the city.
+func (w *World) strands(x, y int) bool {
+	if !w.Shift() || !w.maze[y][x] || !w.inside(w.car.CellX, w.car.CellY) {
+		return false
+	}
+
+	cell := w.cell(x, y)
+	known := true
+	for t := range w.targetRoutes {
+		_, waiting := w.target(t)
+		known = known && (!waiting || len(w.targetRoutes[t]) > 0)
+	}
+	if !known && w.paths.joinedAround(w.maze, x, y) {
+		// Nothing's cut off, but any route through the cell has to be found again
+		for t, route := range w.targetRoutes {
+			if w.routeUses[cell] > 0 && routePosition(route, cell) >= 0 {
+				w.setTargetRoute(t, nil)
+			}
+		}
+		return false
+	}
+
+	if !w.knowRoutes() {
+		return true
+	}
+	for t, route := range w.targetRoutes {
+		if w.routeUses[cell] == 0 {
+			return false // None of the remaining routes need the cell
+		}
+
+		i := routePosition(route, cell)
+		if i < 0 {
+			continue
+		}
+		w.loadRoute(t)
+		if !w.targetPaths.reroute(w.maze, len(route)-1-i) {
+			return true
+		}
+		w.keepDetour(t)
+	}
+	return false
+}
+
+// followCustomer reports whether the car can still reach a customer's doorstep (target t) after they moved, updating
+// the known route to them
+func (w *World) followCustomer(t int) bool {
+	if !w.inside(w.car.CellX, w.car.CellY) {
+		return true
+	}
+	if len(w.targetRoutes[t]) == 0 {
+		w.knowRoutes()
+		return len(w.targetRoutes[t]) > 0
+	}
+
+	w.loadRoute(t)
+	doorstep, _ := w.target(t)
+	if !w.targetPaths.extend(w.maze, doorstep) {
+		return false
+	}
+	w.keepDetour(t)
+	return true
+}
+
+// followCar moves the car's end of every known target route to the cell the car just moved to
+func (w *World) followCar(to int32) {
+	for t, route := range w.targetRoutes {
+		if len(route) == 0 {
+			continue
+		}
+
+		// Backing up along a route (or crossing it) cuts it short, otherwise the route gets one cell longer
+		if i := -1; w.routeUses[to] > 0 {
+			if i = routePosition(route, to); i >= 0 {
+				for _, cell := range route[i+1:] {
+					w.routeUses[cell]--
+				}
+				w.targetRoutes[t] = route[:i+1]
+				continue
+			}
+		}
+		w.routeUses[to]++
+		w.targetRoutes[t] = append(route, to)
+	}
+}
+
+// target returns the cell of a shift's target, and whether it's still waiting for the car: even targets are where
+// each package lies until it's picked up, and odd ones are the doorsteps of each package's customer until it's
+// delivered
+func (w *World) target(t int) (int32, bool) {
+	p := w.packages[t/2]
+	if t%2 == 0 {
+		return w.cell(p.CellX, p.CellY), p.State == Waiting
+	}
+	return w.cell(p.doorstep(w.width, w.height)), p.State != Delivered
+}
+
+// knowRoutes reports whether there's a known route to every target still waiting for the car, searching the whole
+// maze once for any that are missing. The steps the search took back to the car are the routes to everything it
+// reached. Targets that can't be reached are only searched for once a tick, since the walls have to open up first.
+func (w *World) knowRoutes() bool {
+	missing := false
+	for t, route := range w.targetRoutes {
+		_, waiting := w.target(t)
+		missing = missing || (waiting && len(route) == 0)
+	}
+	if !missing {
+		return true
+	}
+	if w.routeSearched == w.tick {
+		return false
+	}
+
+	p := &w.targetPaths
+	car := w.cell(w.car.CellX, w.car.CellY)
+	p.flood(w.maze, car)
+	for t, route := range w.targetRoutes {
+		target, waiting := w.target(t)
+		if !waiting || len(route) > 0 || !p.reached(w.maze, target) {
+			continue
+		}
+
+		route = append(route[:0], target)
+		for cell := target; cell != car; {
+			cell = p.parent[0][cell]
+			route = append(route, cell)
+		}
+		for _, cell := range route {
+			w.routeUses[cell]++
+		}
+		w.targetRoutes[t] = route
+		w.routeShortest[t] = len(route)
+	}
+
+	for t, route := range w.targetRoutes {
+		if _, waiting := w.target(t); waiting && len(route) == 0 {
+			w.routeSearched = w.tick
+			return false
+		}
+	}
+	return true
+}
+
+// keepDetour keeps the pathfinder's route as the known route to a target, after it went a different way to the
+// shortest route that was found. Once the detours have made it twice as long, it's forgotten so that the shortest
+// route is found again, since every cell a route runs through makes more wall changes to check.
+func (w *World) keepDetour(t int) {
+	if len(w.targetPaths.route) > 2*w.routeShortest[t] {
+		w.setTargetRoute(t, nil)
+		return
+	}
+	w.setTargetRoute(t, w.targetPaths.route)
+}
+
+// loadRoute makes the known route to a target the pathfinder's route, from the car to the target
+func (w *World) loadRoute(t int) {
+	route := w.targetRoutes[t]
+	loaded := w.targetPaths.spare[:0]
+	for i := len(route) - 1; i >= 0; i-- {
+		loaded = append(loaded, route[i])
+	}
+	w.targetPaths.setRoute(loaded)
+}
+
+// setTargetRoute keeps a route from the car to a target as the known route to it, or forgets it if it's empty
+func (w *World) setTargetRoute(t int, route []int32) {
+	kept := w.targetRoutes[t]
+	for _, cell := range kept {
+		w.routeUses[cell]--
+	}
+
+	kept = kept[:0]
+	for i := len(route) - 1; i >= 0; i-- {
+		kept = append(kept, route[i])
+		w.routeUses[route[i]]++
+	}
+	w.targetRoutes[t] = kept
+}
+
+// routePosition returns the position of a cell on a target route, or -1 if it isn't on it
+func routePosition(route []int32, cell int32) int {
+	for i, c := range route {
+		if c == cell {
+			return i
+		}
+	}
+	return -1
+}
+
+// goalPackage returns the package the car is heading for: the first one it picked up that it's still carrying, or the
+// first one still waiting to be picked up, or -1 if there's nothing left to do
+func (w *World) goalPackage() int {
+	if len(w.carried) > 0 {
+		return w.carried[0]
+	}
+	for i, p := range w.packages {
+		if p.State == Waiting {
+			return i
+		}
+	}
+	return -1
+}
+
+// goal returns the cell inside the maze the car has to be able to reach: the customer's cell of a single delivery, or
+// during a shift, where the package it's heading for is (or the customer's doorstep, once it's carrying it)
+func (w *World) goal() (int, int) {
+	if !w.Shift() {
+		return w.endX, w.height - 1
+	}
+
+	i := w.goalPackage()
+	switch {
+	case i < 0:
+		return w.car.CellX, w.car.CellY
+	case w.packages[i].State == Carried:
+		return w.packages[i].doorstep(w.width, w.height)
+	default:
+		return w.packages[i].CellX, w.packages[i].CellY
+	}
+}
+
+// pickUp puts the package in the car's cell in the car, if there's room for it
+func (w *World) pickUp() {
+	i := w.packageAt(w.car.CellX, w.car.CellY)
+	if i < 0 || len(w.carried) >= w.config.Capacity {
+		return
+	}
+
+	w.packages[i].State = Carried
+	w.carried = append(w.carried, i)
+	w.setTargetRoute(2*i, nil)
+}
+
+// deliver hands over a package the car is carrying to a customer in the given cell outside the maze, if there's one
+// waiting for it there
+func (w *World) deliver(x, y int) {
+	for k, i := range w.carried {
+		if cx, cy := w.packages[i].Customer(w.width, w.height); cx == x && cy == y {
+			w.packages[i].State = Delivered
+			w.carried = append(w.carried[:k], w.carried[k+1:]...)
+			w.delivered++
+			w.setTargetRoute(2*i+1, nil)
+			return
+		}
+	}
+}
+
+// doorstepAt reports whether a cell is the doorstep of a customer still waiting for a package
+func (w *World) doorstepAt(x, y int) bool {
+	for _, p := range w.packages {
+		if dx, dy := p.doorstep(w.width, w.height); p.State != Delivered && dx == x && dy == y {
+			return true
+		}
+	}
+	return false
+}
+
+// packageAt returns the index of the package waiting in a cell, or -1 if there isn't one
+func (w *World) packageAt(x, y int) int {
+	for i, p := range w.packages {
+		if p.State == Waiting && p.CellX == x && p.CellY == y {
+			return i
+		}
+	}
+	return -1
+}
+
+// Shift reports whether the run is a shift of several packages, rather than a single delivery (which it falls back to
+// if none of the packages could be placed)
+func (w *World) Shift() bool {
+	return len(w.packages) > 0
+}
+
+// Packages returns every package of the shift, or nil for a single delivery
+func (w *World) Packages() []Package {
+	return append([]Package(nil), w.packages...)
+}
+
+// Delivered returns the number of packages delivered so far during a shift
+func (w *World) Delivered() int {
+	return w.delivered
+}
+
+// Score returns the points the shift has earned so far: points for every delivery, less some for the time taken and
+// the moves made. Single deliveries don't score points, they're ranked by time.
+func (w *World) Score() int {
+	if !w.Shift() {
+		return 0
+	}
+	return max(w.delivered*deliveryPoints-int(w.Elapsed().Seconds())*secondPenalty-w.moves*movePenalty, 0)
+}
@@ -0,0 +1,695 @@
+package sim
+
+// The simulation core of Delivery Dash. It has no idea about windows, keyboards or wall-clock time: every call to
+// Step advances the world by one tick using a snapshot of the player's input, and all randomness comes from a
+// generator seeded when the world is created. This makes every run reproducible and lets the game logic be
+// stepped thousands of times in tests without a display.
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	TicksPerSecond = 60  // Number of ticks that make up one second of game time (matches Ebitengine's default TPS)
+	MazeWidth      = 20  // Number of cells wide, unless configured otherwise
+	MazeHeight     = 15  // Number of cells tall, unless configured otherwise
+	MinMazeSize    = 5   // Fewest cells a maze can be wide or tall
+	MaxMazeSize    = 200 // Most cells a maze can be wide or tall
+)
+
+type Direction int
+
+const (
+	Up Direction = iota
+	Right
+	Down
+	Left
+)
+
+// Input is a snapshot of which directions (and whether the use button) the player is holding down during a single tick
+type Input struct {
+	Up, Right, Down, Left bool
+	Use                   bool // Use the power-up the car is carrying
+}
+
+// Config is the size of a world's maze, how chaotic it is, and how it handles the player's input, which aren't decided
+// by the seed and so are stored in replays
+type Config struct {
+	Width          int        `json:"width"`           // Number of cells wide, or 0 for MazeWidth
+	Height         int        `json:"height"`          // Number of cells tall, or 0 for MazeHeight
+	Difficulty     Difficulty `json:"difficulty"`      // How often the walls and customer move, or the zero value for the default preset
+	InputBuffer    int        `json:"inputBuffer"`     // Presses remembered during the move cooldown and made in order once it ends (0 drops them)
+	RepeatDelay    int        `json:"repeatDelay"`     // Ticks a direction has to be held before the car keeps moving that way, or 0 to never repeat
+	RepeatInterval int        `json:"repeatInterval"`  // Ticks between repeated moves while a direction is held (the move cooldown still applies)
+	Generator      string     `json:"generator"`       // Name of the maze generator that builds the starting layout (see Generators)
+	Packages       int        `json:"packages"`        // Number of packages to deliver in a shift, or 0 for a single delivery
+	Capacity       int        `json:"capacity"`        // Packages the car can carry at once during a shift, or 0 for DefaultCapacity
+	Level          *Level     `json:"level,omitempty"` // Hand-made city to play instead of a generated one, which sets the size
+}
+
+// DefaultConfig builds the chaotic city at the usual size and difficulty, remembers a couple of quick presses during
+// the move cooldown, and doesn't repeat held directions
+func DefaultConfig() Config {
+	return Config{
+		Width:       MazeWidth,
+		Height:      MazeHeight,
+		Difficulty:  difficulties[DefaultDifficulty],
+		InputBuffer: 2,
+		Generator:   DefaultGenerator,
+	}
+}
+
+// Failure is why a delivery failed
+type Failure int
+
+const (
+	NotFailed      Failure = iota
+	OutOfFuel              // The car used up its fuel before reaching the customer
+	MissedDeadline         // The delivery took longer than the deadline
+	Crashed                // The car crashed into traffic, on a difficulty where that ends the run
+)
+
+// Car is the player's position in the maze, where row -1 is the start above the maze and the row below the last one
+// is the delivery point
+type Car struct {
+	CellX, CellY int       // Current cell position
+	Direction    Direction // Direction the car is facing
+}
+
+type World struct {
+	width, height  int // Size of the maze in cells
+	car            Car
+	maze           [][]bool // true for walls, false for paths
+	startX, startY int
+	endX, endY     int
+	seed           int64      // Seed the world was created with
+	rng            *rand.Rand // Source of every random decision, so the same seed always builds the same game
+	tick           int        // Number of ticks the world has been stepped
+	lastMazeUpdate int        // Tick of the last end position update
+	wallRow        int        // Next row the walls will shift in
+	gameOver       bool
+	failure        Failure // Why the delivery failed, once it has
+	win            bool
+	moveTimer      int  // Counter for movement cooldown
+	startTick      int  // Tick when the player started moving
+	hasStarted     bool // Whether the player has left the start position
+	finalTick      int  // Tick when the player reached the delivery point
+	moves          int  // Number of moves that took the car to a new cell
+	shortestPath   int  // Fewest moves from the start to the customer in the maze as it was generated, or -1 if blocked
+	deadline       int  // Ticks after leaving the start that the delivery has to be made in, or 0 for no deadline
+	fuel, tank     int  // Moves of fuel left, and how many the tank holds (0 for an endless tank)
+	traffic        []Vehicle
+	crashes        int // Number of times the car has crashed into traffic
+	lastCrash      int // Tick of the last crash
+	penalty        int // Ticks added to the clock by crashes
+	pickups        []Pickup
+	carrying       PowerUp // Power-up the car is carrying
+	frozen         int     // Ticks left of Freeze
+	turbo          int     // Ticks left of Turbo
+	radar          int     // Ticks left of Radar
+	bulldozing     bool    // Whether the car will drive through the next wall it runs into
+	customerPlan   []int   // Places the customer will try to go next, decided ahead of time for Radar
+	packages       []Package
+	carried        []int      // Packages in the car, in the order they were picked up
+	delivered      int        // Number of packages delivered
+	targetPaths    pathfinder // Reusable state for checking that wall changes never cut a shift's car off from its targets
+	targetRoutes   [][]int32  // Known route from each of a shift's targets back to the car, or empty if there isn't one
+	routeUses      []uint8    // Number of known target routes through each cell
+	routeSearched  int        // Tick of the last search that couldn't find a route to every target
+	routeShortest  []int      // Length of the route to each target when it was last found by a search
+	bumped         int        // Tick of the last move that didn't get anywhere, or 0
+	lastInput      Input      // Input from the previous tick, to detect new key presses
+	config         Config
+	paths          pathfinder  // Reusable state for checking that wall changes never trap the car
+	queue          []Direction // Presses waiting for the move cooldown to end, oldest first
+	held           Direction   // Direction most recently pressed, while it's still held down
+	heldTicks      int         // Ticks the held direction has been held down, or 0 when nothing is held
+}
+
+// New creates a world whose maze and every later random decision are derived from seed, sized and handling input as
+// configured
+func New(seed int64, config Config) *World {
+	if config.Level != nil {
+		config.Width, config.Height = config.Level.Size()
+	}
+	if config.Width == 0 {
+		config.Width = MazeWidth
+	}
+	if config.Height == 0 {
+		config.Height = MazeHeight
+	}
+	config.Width = min(max(config.Width, MinMazeSize), MaxMazeSize)
+	config.Height = min(max(config.Height, MinMazeSize), MaxMazeSize)
+	if config.Difficulty == (Difficulty{}) {
+		config.Difficulty = difficulties[DefaultDifficulty]
+	}
+	config.Difficulty = config.Difficulty.clamp()
+	config.Packages = min(max(config.Packages, 0), MaxPackages)
+	if config.Capacity <= 0 {
+		config.Capacity = DefaultCapacity
+	}
+	config.InputBuffer = max(config.InputBuffer, 0)
+	config.RepeatDelay = max(config.RepeatDelay, 0)
+	config.RepeatInterval = max(config.RepeatInterval, 1)
+	generator, ok := Generator(config.Generator)
+	if !ok {
+		config.Generator = DefaultGenerator
+		generator = generators[DefaultGenerator]
+	}
+
+	width, height := config.Width, config.Height
+
+	// Set start and end positions outside the maze
+	startX := width / 2
+	startY := -1 // One cell above the maze
+	endX := width / 2
+	endY := height // One cell below the maze
+
+	w := &World{
+		car: Car{
+			CellX:     startX,
+			CellY:     startY,
+			Direction: Down,
+		},
+		width:  width,
+		height: height,
+		startX: startX,
+		startY: startY,
+		endX:   endX,
+		endY:   endY,
+		seed:   seed,
+		rng:    rand.New(rand.NewSource(seed)),
+		config: config,
+	}
+
+	// Create initial maze layout, with a route from the entrance to the customer, or lay out the level with the car
+	// and customer somewhere in their lanes
+	if level := config.Level; level != nil {
+		starts, ends := level.columns(level.Start, 0), level.columns(level.Customer, height-1)
+		w.startX = starts[w.rng.Intn(len(starts))]
+		w.endX = ends[w.rng.Intn(len(ends))]
+		w.car.CellX = w.startX
+		w.maze = level.layout(true)
+	} else {
+		w.maze = generator.Generate(w.rng, w.width, w.height, startX, endX)
+	}
+	w.paths = newPathfinder(w.width, w.height)
+	w.shortestPath = w.pathLength()
+
+	// Give the car time and fuel for the shortest route and then some, as set by the difficulty
+	route := w.shortestPath
+	if route < 0 {
+		route = w.width * w.height // Walled in at the start, so allow for the walls opening up again
+	}
+	route *= max(config.Packages*3/2, 1) // Half as long again as a trip across the maze for each package of a shift
+	w.deadline = int(math.Ceil(config.Difficulty.Deadline * float64(route*config.Difficulty.MoveCooldown)))
+	w.tank = int(math.Ceil(config.Difficulty.Fuel * float64(route)))
+	w.fuel = w.tank
+
+	w.spawnTraffic()
+	for i := 0; i < w.config.Difficulty.PowerUps; i++ {
+		w.spawnPowerUp()
+	}
+	if config.Packages > 0 {
+		w.spawnPackages()
+	}
+
+	return w
+}
+
+// Step advances the world by one tick, using in as the directions the player is holding down
+func (w *World) Step(in Input) {
+	if w.gameOver || w.win {
+		return
+	}
+
+	w.tick++
+
+	// Update movement cooldown
+	if w.moveTimer > 0 {
+		w.moveTimer--
+	}
+	w.tickPowerUps()
+
+	// Check for maze updates
+	if w.tick-w.lastMazeUpdate >= w.config.Difficulty.CustomerInterval && w.Shift() {
+		w.moveCustomers()
+		w.lastMazeUpdate = w.tick
+	} else if w.tick-w.lastMazeUpdate >= w.config.Difficulty.CustomerInterval {
+		// Try to move the end position multiple times
+		for i := 0; i < 2; i++ { // Try to move up to 2 times per update
+			oldEndX := w.endX
+			// Try to jump to a random position at the bottom
+			newEndX := w.nextCustomerX()
+			w.endX = newEndX
+			// If the new position would trap the player, revert the change
+			if w.wouldTrapPlayer(w.endX, w.height-1) {
+				w.endX = oldEndX
+			}
+		}
+
+		w.lastMazeUpdate = w.tick
+	}
+
+	// Shift the walls a few rows at a time (separate from end position updates), sweeping down the whole maze once
+	// every wall update interval so that no single tick has to check every cell of a big maze. The sweep carries on
+	// while the walls are frozen, just without shifting them.
+	interval := w.config.Difficulty.WallInterval
+	sweptThrough := w.height * ((w.tick-1)%interval + 1) / interval
+	for ; w.wallRow < sweptThrough; w.wallRow++ {
+		if w.frozen == 0 {
+			w.shiftWalls(w.wallRow)
+		}
+	}
+	if w.wallRow == w.height {
+		w.wallRow = 0
+	}
+
+	w.handleInput(in)
+	w.checkCrash()
+	w.moveTraffic()
+	if w.gameOver {
+		return // Crashed
+	}
+
+	// Check for win condition, and then for running out of fuel or time
+	switch {
+	case w.Shift() && w.delivered == len(w.packages), w.car.CellY == w.height && w.car.CellX == w.endX:
+		w.win = true
+		w.finalTick = w.tick
+	case w.tank > 0 && w.fuel == 0:
+		w.fail(OutOfFuel)
+	case w.deadline > 0 && w.hasStarted && w.tick-w.startTick+w.penalty >= w.deadline:
+		w.fail(MissedDeadline)
+	}
+}
+
+// fail ends the run without a delivery
+func (w *World) fail(reason Failure) {
+	w.gameOver = true
+	w.failure = reason
+	w.finalTick = w.tick
+}
+
+// shiftWalls toggles a random share of the walls in a row (set by the difficulty, or each region of a level), except for the entrance, exit, and
+// permanent walls, keeping only the changes that leave the player a route to the customer
+func (w *World) shiftWalls(y int) {
+	goalX, goalY := w.goal()
+	for x := range w.maze[y] {
+		chance := w.wallChance(x, y)
+		isProtected := chance == 0 || // Permanent walls and streets
+			(x == w.startX && y == 0) || // Start position
+			(x == goalX && y == goalY && !w.maze[y][x]) || // End position, or where a shift's car is heading once it's open
+			w.packageAt(x, y) >= 0 || // Packages waiting to be picked up
+			(w.doorstepAt(x, y) && !w.maze[y][x]) || // and open doorsteps of customers waiting for them
+			w.trafficAt(x, y) >= 0 || // Walls never close on traffic
+			w.pickupAt(x, y) >= 0 // or power-ups
+
+		if !isProtected && w.rng.Float32() < float32(chance) {
+			// Try the change
+			w.maze[y][x] = !w.maze[y][x]
+			// If it would trap the player, revert the change
+			if w.wouldTrapPlayer(x, y) || w.strands(x, y) {
+				w.maze[y][x] = !w.maze[y][x]
+			}
+		}
+	}
+}
+
+// diagonalRows returns how many rows the two permanent diagonal walls run down from the top corners: halfway down the
+// maze, or until just before they'd meet in a narrow one
+func diagonalRows(width, height int) int {
+	return min(height/2, (width-2)/2)
+}
+
+// onDiagonal reports whether a cell is part of the permanent diagonal walls, which are two cells wide
+func onDiagonal(width, height, x, y int) bool {
+	return y < diagonalRows(width, height) && (x == y || x == y+1 || x == width-1-y || x == width-2-y)
+}
+
+// inCenterCross reports whether a cell is part of the permanent cross of walls in the middle of the maze
+func inCenterCross(width, height, x, y int) bool {
+	centerX, centerY := width/2, height/2
+	return (x == centerX && y >= centerY-1 && y <= centerY+1) || (y == centerY && x >= centerX-1 && x <= centerX+1)
+}
+
+// wouldTrapPlayer reports whether the change just made to the cell at (x, y) (toggling it, or moving the customer
+// there) cut the car off from the customer. The city stays still while the car waits at the entrance.
+func (w *World) wouldTrapPlayer(x, y int) bool {
+	carX, carY := w.car.CellX, w.car.CellY
+	if carY < 0 || carY >= w.height {
+		return true
+	}
+
+	car, end, cell := w.cell(carX, carY), w.cell(w.goal()), w.cell(x, y)
+	known := w.paths.hasRoute(car, end)
+
+	switch {
+	case cell == end && w.paths.startsAt(car):
+		// The customer moved, so look for a way from there back to the route to where they were
+		return !w.paths.extend(w.maze, end)
+	case cell == end:
+		return !w.paths.findRoute(w.maze, car, end)
+	case !w.maze[y][x]:
+		return false // Opening a cell never cuts anything off
+	case cell == car:
+		return true
+	case known && w.paths.onRoute(cell) < 0:
+		return false // The route doesn't need the cell
+	case known:
+		return !w.paths.reroute(w.maze, w.paths.onRoute(cell))
+	case w.paths.joinedAround(w.maze, x, y):
+		w.paths.forget() // The cell might have been on an old route
+		return false
+	default:
+		return !w.paths.findRoute(w.maze, car, end)
+	}
+}
+
+// cell returns the number the pathfinder knows a cell by
+func (w *World) cell(x, y int) int32 {
+	return int32(y*w.width + x)
+}
+
+func (w *World) moveCar(dir Direction) {
+	// Calculate the target position based on direction, and turn the car to face it
+	newCellX, newCellY := ahead(w.car, dir)
+	w.car.Direction = dir
+
+	// Special case for start position (above maze)
+	if w.car.CellY == -1 {
+		if newCellY == 0 && !w.maze[0][newCellX] {
+			// Allow movement into the maze
+			w.car.CellX = newCellX
+			w.car.CellY = newCellY
+			w.moves++
+			w.paths.moveStart(w.cell(newCellX, newCellY))
+			w.followCar(w.cell(newCellX, newCellY))
+			// Start the timer when leaving the start position
+			if !w.hasStarted {
+				w.hasStarted = true
+				w.startTick = w.tick
+			}
+		}
+		return
+	}
+
+	// During a shift, driving out of the maze toward a customer delivers their package, leaving the car where it is
+	if w.Shift() {
+		if !w.inside(newCellX, newCellY) {
+			w.deliver(newCellX, newCellY)
+			return
+		}
+	} else if w.car.CellY == w.height-1 && dir == Down && newCellX == w.endX {
+		// Special case for end position (below maze)
+		// Allow movement to the end position
+		w.car.CellX = newCellX
+		w.car.CellY = newCellY
+		w.moves++
+		return
+	}
+
+	// Normal maze movement, through a wall when bulldozing
+	w.bulldoze(dir)
+	if w.drive(&w.car, dir) {
+		w.moves++
+		w.paths.moveStart(w.cell(newCellX, newCellY))
+		w.followCar(w.cell(newCellX, newCellY))
+	}
+}
+
+// drive turns a car to face a direction and moves it to the next cell that way if it's an open cell of the maze,
+// reporting whether it moved. The player's car and the traffic all drive by these rules.
+func (w *World) drive(c *Car, dir Direction) bool {
+	x, y := ahead(*c, dir)
+	c.Direction = dir
+	if !w.inside(x, y) || w.maze[y][x] {
+		return false
+	}
+
+	c.CellX, c.CellY = x, y
+	return true
+}
+
+// ahead returns the cell next to a car in a direction
+func ahead(c Car, dir Direction) (int, int) {
+	switch dir {
+	case Up:
+		return c.CellX, c.CellY - 1
+	case Right:
+		return c.CellX + 1, c.CellY
+	case Down:
+		return c.CellX, c.CellY + 1
+	default:
+		return c.CellX - 1, c.CellY
+	}
+}
+
+// inside reports whether a cell is inside the maze
+func (w *World) inside(x, y int) bool {
+	return x >= 0 && x < w.width && y >= 0 && y < w.height
+}
+
+// pathLength returns the fewest moves from the start, through the maze as it is now, to the customer, or -1 if the
+// customer can't be reached, counting the move into the maze and the move out of it to the customer
+func (w *World) pathLength() int {
+	if !w.paths.findRoute(w.maze, w.cell(w.startX, 0), w.cell(w.endX, w.height-1)) {
+		return -1
+	}
+
+	return len(w.paths.route) + 1
+}
+
+// handleInput uses the power-up being carried when the use button is pressed, queues every new press and repeats of a
+// held direction, then makes the oldest queued move once the move cooldown is over
+func (w *World) handleInput(in Input) {
+	if in.Use && !w.lastInput.Use {
+		w.usePowerUp()
+	}
+
+	for _, dir := range in.pressed(w.lastInput) {
+		// A press while the car is free to move is made straight away, so only presses during the cooldown (or
+		// several at once) need room in the buffer
+		limit := w.config.InputBuffer
+		if w.moveTimer == 0 {
+			limit++
+		}
+		if len(w.queue) < limit {
+			w.queue = append(w.queue, dir)
+		}
+
+		w.held = dir
+		w.heldTicks = 0
+	}
+
+	// Keep moving in the direction that's held down, once it's been held long enough
+	if in.holds(w.held) {
+		w.heldTicks++
+		repeating := w.config.RepeatDelay > 0 && w.heldTicks >= w.config.RepeatDelay
+		if repeating && (w.heldTicks-w.config.RepeatDelay)%w.config.RepeatInterval == 0 && len(w.queue) == 0 {
+			w.queue = append(w.queue, w.held)
+		}
+	} else {
+		w.heldTicks = 0
+	}
+
+	if w.moveTimer == 0 && len(w.queue) > 0 {
+		moves := w.moves
+		w.moveCar(w.queue[0])
+		switch {
+		case w.moves == moves:
+			w.bumped = w.tick // Nowhere to go (or a package handed over), which the window shows as a little bump
+		case w.tank > 0:
+			w.fuel-- // Only moves that get somewhere use fuel, not bumping into walls
+		}
+		w.collect()
+		w.pickUp()
+		w.queue = w.queue[1:]
+		w.moveTimer = w.config.Difficulty.MoveCooldown
+		if w.turbo > 0 {
+			w.moveTimer = max(w.moveTimer/2, 1)
+		}
+	}
+
+	w.lastInput = in
+}
+
+// pressed returns the directions held down in this input that weren't in the previous one
+func (in Input) pressed(last Input) []Direction {
+	var dirs []Direction
+	for _, dir := range []Direction{Up, Right, Down, Left} {
+		if in.holds(dir) && !last.holds(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// holds reports whether the direction is held down in this input
+func (in Input) holds(dir Direction) bool {
+	switch dir {
+	case Up:
+		return in.Up
+	case Right:
+		return in.Right
+	case Down:
+		return in.Down
+	default:
+		return in.Left
+	}
+}
+
+// Config returns the size of the world's maze, how chaotic it is, and how it handles the player's input
+func (w *World) Config() Config {
+	return w.config
+}
+
+// Size returns the number of cells the maze is wide and tall
+func (w *World) Size() (int, int) {
+	return w.width, w.height
+}
+
+// Seed returns the seed the world was created with
+func (w *World) Seed() int64 {
+	return w.seed
+}
+
+// Car returns the player's car
+func (w *World) Car() Car {
+	return w.car
+}
+
+// Wall reports whether the cell at x, y inside the maze is a wall
+func (w *World) Wall(x, y int) bool {
+	return w.maze[y][x]
+}
+
+// Start returns the cell where the car starts, just above the maze
+func (w *World) Start() (int, int) {
+	return w.startX, w.startY
+}
+
+// End returns the cell of the moving delivery point, just below the maze (a shift's customers are in Packages instead)
+func (w *World) End() (int, int) {
+	return w.endX, w.endY
+}
+
+// Tick returns the number of ticks the world has been stepped
+func (w *World) Tick() int {
+	return w.tick
+}
+
+// Bumped returns the tick of the last move that didn't get the car anywhere, like driving into a wall, or 0 if there
+// hasn't been one
+func (w *World) Bumped() int {
+	return w.bumped
+}
+
+// Started reports whether the car has left the start position and the timer is running
+func (w *World) Started() bool {
+	return w.hasStarted
+}
+
+// Won reports whether the car has reached the delivery point
+func (w *World) Won() bool {
+	return w.win
+}
+
+// GameOver reports whether the delivery has failed
+func (w *World) GameOver() bool {
+	return w.gameOver
+}
+
+// Traffic returns every vehicle driving around the maze
+func (w *World) Traffic() []Vehicle {
+	return append([]Vehicle(nil), w.traffic...)
+}
+
+// Crashes returns the number of times the car has crashed into traffic
+func (w *World) Crashes() int {
+	return w.crashes
+}
+
+// PowerUps returns every power-up lying in the maze
+func (w *World) PowerUps() []Pickup {
+	return append([]Pickup(nil), w.pickups...)
+}
+
+// Carrying returns the power-up the car is carrying, or NoPowerUp
+func (w *World) Carrying() PowerUp {
+	return w.carrying
+}
+
+// Frozen returns how much longer the walls are frozen for
+func (w *World) Frozen() time.Duration {
+	return ticksToDuration(w.frozen)
+}
+
+// Turbo returns how much longer the car moves faster for
+func (w *World) Turbo() time.Duration {
+	return ticksToDuration(w.turbo)
+}
+
+// Bulldozing reports whether the car will drive through the next wall it runs into
+func (w *World) Bulldozing() bool {
+	return w.bulldozing
+}
+
+// Radar returns the places along the bottom row the customer will try to go next while Radar is in use, or nil
+func (w *World) Radar() []int {
+	if w.radar == 0 {
+		return nil
+	}
+	return append([]int(nil), w.customerPlan...)
+}
+
+// Failure returns why the delivery failed, or NotFailed if it hasn't
+func (w *World) Failure() Failure {
+	return w.failure
+}
+
+// Fuel returns how many moves of fuel are left and how many the tank holds, where a tank of 0 never runs out
+func (w *World) Fuel() (int, int) {
+	return w.fuel, w.tank
+}
+
+// Deadline returns how long after leaving the start the delivery has to be made, or 0 if there's no deadline
+func (w *World) Deadline() time.Duration {
+	return ticksToDuration(w.deadline)
+}
+
+// Moves returns the number of moves that took the car to a new cell
+func (w *World) Moves() int {
+	return w.moves
+}
+
+// ShortestPath returns the fewest moves it would have taken to reach the customer in the maze as it was generated,
+// or -1 if the walls blocked every route at the start
+func (w *World) ShortestPath() int {
+	return w.shortestPath
+}
+
+// Elapsed returns the game time since the car left the start plus any time lost to crashes, stopping once the
+// delivery is made
+func (w *World) Elapsed() time.Duration {
+	if !w.hasStarted {
+		return 0
+	}
+
+	end := w.tick
+	if w.win || w.gameOver {
+		end = w.finalTick
+	}
+
+	return ticksToDuration(end - w.startTick + w.penalty)
+}
+
+func secondsToTicks(seconds float64) int {
+	return int(math.Round(seconds * TicksPerSecond))
+}
+
+func ticksToDuration(ticks int) time.Duration {
+	return time.Duration(ticks) * time.Second / TicksPerSecond
+}
@@ -0,0 +1,162 @@
+package sim
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// randomInputs returns a run of made-up inputs that favour driving down the maze, the same for the same seed
+func randomInputs(seed int64, ticks int) []Input {
+	rng := rand.New(rand.NewSource(seed))
+	inputs := make([]Input, ticks)
+	for i := range inputs {
+		if i%3 != 0 {
+			continue // Let go of the keys between presses
+		}
+		switch rng.Intn(5) {
+		case 0, 4:
+			inputs[i].Down = true
+		case 1:
+			inputs[i].Left = true
+		case 2:
+			inputs[i].Right = true
+		case 3:
+			inputs[i].Up = true
+		}
+	}
+	return inputs
+}
+
+// sameState reports the first difference between what two worlds show, or "" if there's none
+func sameState(a, b *World) string {
+	switch {
+	case a.Tick() != b.Tick():
+		return "tick"
+	case a.Car() != b.Car():
+		return "car"
+	case a.Moves() != b.Moves():
+		return "moves"
+	case a.Won() != b.Won() || a.Failure() != b.Failure():
+		return "result"
+	}
+	ax, ay := a.End()
+	if bx, by := b.End(); ax != bx || ay != by {
+		return "customer"
+	}
+
+	width, height := a.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if a.Wall(x, y) != b.Wall(x, y) {
+				return "walls"
+			}
+		}
+	}
+	return ""
+}
+
+func TestStepIsDeterministic(t *testing.T) {
+	for _, difficulty := range Difficulties() {
+		preset, _ := DifficultyPreset(difficulty)
+		for _, generator := range Generators() {
+			config := DefaultConfig()
+			config.Difficulty = preset
+			config.Generator = generator
+
+			for seed := int64(0); seed < 5; seed++ {
+				a, b := New(seed, config), New(seed, config)
+				for tick, in := range randomInputs(seed, 3000) {
+					a.Step(in)
+					b.Step(in)
+					if diff := sameState(a, b); diff != "" {
+						t.Fatalf("%s %s seed %d: expected the same %s at tick %d", difficulty, generator, seed, diff, tick)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSeedChangesWorld(t *testing.T) {
+	a, b := New(1, DefaultConfig()), New(2, DefaultConfig())
+	if sameState(a, b) == "" {
+		t.Errorf("expected different seeds to build different cities")
+	}
+}
+
+func TestReplayReproducesRun(t *testing.T) {
+	config := DefaultConfig()
+	world := New(7, config)
+	replay := &Replay{GameVersion: "1.0.0", Seed: 7, Config: config}
+	for _, in := range randomInputs(7, 3000) {
+		replay.Record(in)
+		world.Step(in)
+	}
+
+	var buf bytes.Buffer
+	if err := replay.Write(&buf); err != nil {
+		t.Fatalf("expected the replay to write, got %v", err)
+	}
+	read, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatalf("expected the replay to read back, got %v", err)
+	}
+	if read.GameVersion != replay.GameVersion || read.Seed != replay.Seed || len(read.Inputs) != len(replay.Inputs) {
+		t.Fatalf("expected version %q, seed %d and %d inputs, got %q, %d and %d", replay.GameVersion, replay.Seed, len(replay.Inputs), read.GameVersion, read.Seed, len(read.Inputs))
+	}
+
+	again := New(read.Seed, read.Config)
+	for _, in := range read.Inputs {
+		again.Step(in)
+	}
+	if diff := sameState(world, again); diff != "" {
+		t.Errorf("expected the replay to end in the same state, got a different %s", diff)
+	}
+}
+
+func TestShiftWithoutPackagesIsSingleDelivery(t *testing.T) {
+	config := DefaultConfig()
+	config.Packages = 1
+	w := New(1, config)
+	w.packages = nil // As if there was nowhere the car could reach to place it
+
+	w.Step(Input{})
+	if w.Shift() || w.Won() {
+		t.Errorf("expected a shift with nothing to deliver to be played as a single delivery, got shift %v and won %v", w.Shift(), w.Won())
+	}
+}
+
+func TestShiftTargetsStayReachable(t *testing.T) {
+	for _, generator := range Generators() {
+		for seed := int64(0); seed < 4; seed++ {
+			config := DefaultConfig()
+			config.Generator = generator
+			config.Packages = 4
+			w := New(seed, config)
+
+			// Customers move anywhere while the car waits at the entrance, so only once the car has been able to reach
+			// every target must it always be able to
+			reachedAll := false
+			for tick, in := range randomInputs(seed, 2000) {
+				w.Step(in)
+				if !w.inside(w.car.CellX, w.car.CellY) || w.Won() {
+					continue
+				}
+
+				reachable := true
+				for target := range w.targetRoutes {
+					cell, waiting := w.target(target)
+					reachable = reachable && (!waiting || connected(w.maze, w.car.CellX, w.car.CellY, int(cell)%w.width, int(cell)/w.width))
+				}
+				if reachedAll && !reachable {
+					t.Fatalf("%s seed %d: expected every target to stay reachable, got one cut off at tick %d", generator, seed, tick)
+				}
+				reachedAll = reachedAll || reachable
+			}
+			if !reachedAll {
+				t.Fatalf("%s seed %d: expected every target to be reachable at some point", generator, seed)
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package sim
+
+import "fmt"
+
+const (
+	crashPenalty = 2 * TicksPerSecond // Ticks a crash adds to the clock, when crashes don't end the run
+	crashGrace   = TicksPerSecond     // Ticks after a crash before the car can crash again
+	spawnTries   = 100                // Random cells tried for each vehicle before giving up on it
+)
+
+// Behavior is how a traffic vehicle picks where to drive
+type Behavior int
+
+const (
+	Patrol Behavior = iota // Drives straight on, turning around whenever it's blocked
+	Wander                 // Turns at random, rarely doubling back
+	Chase                  // Heads toward the player's car
+)
+
+// behaviors are handed out to vehicles in turn as they're spawned
+var behaviors = []Behavior{Patrol, Wander, Chase}
+
+// behaviorNames are how each behavior is written in level files
+var behaviorNames = map[Behavior]string{
+	Patrol: "patrol",
+	Wander: "wander",
+	Chase:  "chase",
+}
+
+// Behaviors returns every behavior traffic can have, in the order they're handed out
+func Behaviors() []Behavior {
+	return append([]Behavior(nil), behaviors...)
+}
+
+// String returns the name of the behavior (e.g. patrol)
+func (b Behavior) String() string {
+	return behaviorNames[b]
+}
+
+// MarshalText writes the behavior by its name, so that level files are readable
+func (b Behavior) MarshalText() ([]byte, error) {
+	name, ok := behaviorNames[b]
+	if !ok {
+		return nil, fmt.Errorf("unknown behavior %d", int(b))
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText reads a behavior by its name
+func (b *Behavior) UnmarshalText(text []byte) error {
+	for behavior, name := range behaviorNames {
+		if name == string(text) {
+			*b = behavior
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown behavior %q (choose from patrol, wander, chase)", text)
+}
+
+// Vehicle is a piece of traffic driving through the maze, which moves by the same rules as the player's car
+type Vehicle struct {
+	Car
+	Behavior Behavior
+}
+
+// spawnTraffic places the difficulty's vehicles on random open cells away from the entrance, facing random ways, or a
+// level's hazards where it says
+func (w *World) spawnTraffic() {
+	if level := w.config.Level; level != nil && len(level.Hazards) > 0 {
+		for _, hazard := range level.Hazards {
+			w.traffic = append(w.traffic, Vehicle{
+				Car:      Car{CellX: hazard.X, CellY: hazard.Y, Direction: Direction(w.rng.Intn(4))},
+				Behavior: hazard.Behavior,
+			})
+		}
+		return
+	}
+
+	for i := 0; i < w.config.Difficulty.Traffic; i++ {
+		for try := 0; try < spawnTries; try++ {
+			x, y := w.rng.Intn(w.width), w.height/3+w.rng.Intn(w.height-w.height/3)
+			if w.maze[y][x] || w.trafficAt(x, y) >= 0 {
+				continue
+			}
+
+			w.traffic = append(w.traffic, Vehicle{
+				Car:      Car{CellX: x, CellY: y, Direction: Direction(w.rng.Intn(4))},
+				Behavior: behaviors[i%len(behaviors)],
+			})
+			break
+		}
+	}
+}
+
+// moveTraffic moves every vehicle once the traffic's cooldown is over, which is half as long again as the car's
+func (w *World) moveTraffic() {
+	if len(w.traffic) == 0 || w.tick%(w.config.Difficulty.MoveCooldown*3/2) != 0 {
+		return
+	}
+
+	for i := range w.traffic {
+		v := &w.traffic[i]
+		if dir, ok := w.trafficDirection(v); ok {
+			w.drive(&v.Car, dir)
+		}
+		w.checkCrash()
+	}
+}
+
+// trafficDirection picks where a vehicle drives next, or false if it's walled in
+func (w *World) trafficDirection(v *Vehicle) (Direction, bool) {
+	var open []Direction
+	for _, dir := range []Direction{Up, Right, Down, Left} {
+		if x, y := ahead(v.Car, dir); w.inside(x, y) && !w.maze[y][x] {
+			open = append(open, dir)
+		}
+	}
+	if len(open) == 0 {
+		return 0, false
+	}
+
+	back := (v.Direction + 2) % 4
+	forward := make([]Direction, 0, len(open)) // Every open direction except doubling back
+	for _, dir := range open {
+		if dir != back {
+			forward = append(forward, dir)
+		}
+	}
+	if len(forward) == 0 {
+		return back, true // A dead end
+	}
+
+	switch v.Behavior {
+	case Patrol:
+		for _, dir := range forward {
+			if dir == v.Direction {
+				return dir, true
+			}
+		}
+		return back, true
+	case Chase:
+		// Take whichever way gets closest to the car, as the crow flies
+		best, bestDistance := forward[0], -1
+		for _, dir := range forward {
+			x, y := ahead(v.Car, dir)
+			distance := abs(x-w.car.CellX) + abs(y-w.car.CellY)
+			if bestDistance < 0 || distance < bestDistance {
+				best, bestDistance = dir, distance
+			}
+		}
+		return best, true
+	default:
+		return forward[w.rng.Intn(len(forward))], true
+	}
+}
+
+// checkCrash handles the car and a vehicle being in the same cell, which either ends the run or costs time, and
+// turns the vehicle around so that it drives away
+func (w *World) checkCrash() {
+	if w.car.CellY < 0 || w.car.CellY >= w.height || w.gameOver {
+		return // The car is safe outside the maze
+	}
+	if w.crashes > 0 && w.tick-w.lastCrash < crashGrace {
+		return
+	}
+
+	i := w.trafficAt(w.car.CellX, w.car.CellY)
+	if i < 0 {
+		return
+	}
+
+	w.crashes++
+	w.lastCrash = w.tick
+	w.traffic[i].Direction = (w.traffic[i].Direction + 2) % 4
+	if w.config.Difficulty.CrashesEnd {
+		w.fail(Crashed)
+		return
+	}
+	w.penalty += crashPenalty
+}
+
+// trafficAt returns the index of the vehicle in a cell, or -1 if there isn't one
+func (w *World) trafficAt(x, y int) int {
+	for i, v := range w.traffic {
+		if v.CellX == x && v.CellY == y {
+			return i
+		}
+	}
+	return -1
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
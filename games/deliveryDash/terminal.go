@@ -0,0 +1,425 @@
+package deliveryDash
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/emmahsax/go-games/games/deliveryDash/sim"
+	"github.com/emmahsax/go-games/input"
+	"github.com/emmahsax/go-games/terminal"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// glyphs are the two-column wide cells the terminal draws the maze with
+type glyphs struct {
+	wall, path, border, start, end string
+	car                            [4]string // Indexed by sim.Direction
+	full, empty                    string    // Single-column cells of the fuel and deadline gauges
+	radar                          string    // Where Radar shows the customer might go next
+}
+
+var (
+	unicodeGlyphs = glyphs{
+		wall:   terminal.White + "██",
+		path:   "  ",
+		border: terminal.Dim + "░░",
+		start:  terminal.BgGreen + "  ",
+		end:    terminal.BgBlue + "  ",
+		car:    [4]string{"▲ ", "▶ ", "▼ ", "◀ "},
+		full:   "█",
+		empty:  "░",
+		radar:  "▒▒",
+	}
+	asciiGlyphs = glyphs{
+		wall:   terminal.White + "##",
+		path:   "  ",
+		border: terminal.Dim + "..",
+		start:  terminal.BgGreen + "S ",
+		end:    terminal.BgBlue + "E ",
+		car:    [4]string{"^ ", "> ", "v ", "< "},
+		full:   "#",
+		empty:  "-",
+		radar:  "::",
+	}
+)
+
+// trafficColors are the colors of the traffic, by how each vehicle drives (matching the window's sprites)
+var trafficColors = map[sim.Behavior]string{
+	sim.Patrol: terminal.Yellow,
+	sim.Wander: terminal.Cyan,
+	sim.Chase:  terminal.Magenta,
+}
+
+// powerUpTerminalColors are the colors each power-up is drawn in (matching the window's)
+var powerUpTerminalColors = map[sim.PowerUp]string{
+	sim.Freeze:    terminal.Cyan,
+	sim.Bulldozer: terminal.Yellow,
+	sim.Turbo:     terminal.Green,
+	sim.Radar:     terminal.Magenta,
+}
+
+// packageTerminalColors are the colors each package of a shift and its customer are drawn in, in turn (matching the
+// window's)
+var packageTerminalColors = []string{terminal.Blue, terminal.Magenta, terminal.Cyan, terminal.Green, terminal.Yellow}
+
+// runTerminal plays the game on the command-line instead of in a window, sharing the same session and simulation
+func runTerminal(opts Options, ascii bool) (*session, error) {
+	term, err := terminal.Open()
+	if err != nil {
+		return nil, fmt.Errorf("could not use the terminal: %w", err)
+	}
+	defer term.Close()
+
+	s := newSession(opts)
+	cells := unicodeGlyphs
+	if ascii {
+		cells = asciiGlyphs
+	}
+
+	ticker := time.NewTicker(time.Second / sim.TicksPerSecond)
+	defer ticker.Stop()
+
+	titleScreen := opts.Playback == nil // Start with title screen, unless watching a replay
+	var menu pauseMenu                  // Pause menu, opened with ESC or P
+	var presses []sim.Input             // Direction and use keys pressed since they were last given to the world
+	var last sim.Input                  // Input given to the world on the previous tick
+
+	for {
+		select {
+		case event, ok := <-term.Keys():
+			if !ok {
+				return s, nil
+			}
+			if event.Key == terminal.KeyInterrupt {
+				return s, nil
+			}
+			actions := terminalActions(opts.Bindings, event)
+
+			// Handle title screen, only accepting Confirm to start, Left and Right to pick the difficulty (or level),
+			// and Back or Q to exit
+			if titleScreen {
+				switch {
+				case actions[input.Back] || event.Rune == 'q' || event.Rune == 'Q':
+					return s, nil
+				case actions[input.Confirm]:
+					titleScreen = false
+				case actions[input.Left]:
+					s = s.pick(-1)
+				case actions[input.Right]:
+					s = s.pick(1)
+				}
+				continue
+			}
+
+			// Handle the pause menu, without stepping the world so that everything stays frozen
+			if menu.open {
+				var choice menuChoice
+				switch {
+				case actions[input.Up]:
+					choice = menu.press(menuUp)
+				case actions[input.Down]:
+					choice = menu.press(menuDown)
+				case actions[input.Confirm]:
+					choice = menu.press(menuConfirm)
+				case actions[input.Back], actions[input.Pause]:
+					choice = menu.press(menuBack)
+				default:
+					continue
+				}
+
+				switch choice {
+				case choiceRestartSameSeed:
+					s = s.restart(true)
+				case choiceRestartNewMaze:
+					s = s.restart(false)
+				case choiceQuitToTitle:
+					s = s.restart(false)
+					titleScreen = true
+				case choiceExit:
+					return s, nil
+				}
+				presses, last = nil, sim.Input{}
+				continue
+			}
+
+			switch {
+			case actions[input.Pause]:
+				menu.show()
+			case s.finished() && (actions[retryAction] || actions[restartAction]):
+				// Once the run is over, restart plays again on a new maze (or the next level of the campaign) and retry
+				// plays the same seed again
+				s = s.restart(actions[retryAction])
+				presses, last = nil, sim.Input{}
+			case event.Rune == 'q' || event.Rune == 'Q':
+				return s, nil
+			case actions[input.Up]:
+				presses = append(presses, inputFor(sim.Up))
+			case actions[input.Right]:
+				presses = append(presses, inputFor(sim.Right))
+			case actions[input.Down]:
+				presses = append(presses, inputFor(sim.Down))
+			case actions[input.Left]:
+				presses = append(presses, inputFor(sim.Left))
+			case actions[useAction]:
+				presses = append(presses, sim.Input{Use: true})
+			}
+		case <-ticker.C:
+			if !titleScreen && !menu.open {
+				// Terminals only report key presses, never releases, so each press is held for a single tick and
+				// followed by a tick with nothing held, letting the world see every press as a new one
+				var in sim.Input
+				if last == (sim.Input{}) && len(presses) > 0 {
+					in = presses[0]
+					presses = presses[1:]
+				}
+				last = in
+
+				s.step(in)
+			}
+
+			cols, rows, err := term.Size()
+			if err != nil {
+				return s, err
+			}
+			if err := term.Draw(renderTerminal(s, titleScreen, &menu, cells, cols, rows)); err != nil {
+				return s, err
+			}
+		}
+	}
+}
+
+// terminalActions returns the actions a key press read from the terminal is bound to. Terminals can't tell which
+// keys are held down, so the press is turned back into the keys that typed it (e.g. R into Shift+R), falling back to
+// the key without Shift so that W still moves up with Caps Lock on.
+func terminalActions(bindings input.Bindings, event terminal.Event) map[input.Action]bool {
+	keys := terminalKeys(event)
+	matched := bindings.Match(keys...)
+	if len(matched) == 0 && len(keys) == 2 && keys[0] == ebiten.KeyShift {
+		matched = bindings.Match(keys[1])
+	}
+
+	actions := make(map[input.Action]bool, len(matched))
+	for _, action := range matched {
+		actions[action] = true
+	}
+	return actions
+}
+
+// terminalKeys returns the keys that would have been held down to type a key press
+func terminalKeys(event terminal.Event) []ebiten.Key {
+	switch event.Key {
+	case terminal.KeyUp:
+		return []ebiten.Key{ebiten.KeyArrowUp}
+	case terminal.KeyDown:
+		return []ebiten.Key{ebiten.KeyArrowDown}
+	case terminal.KeyLeft:
+		return []ebiten.Key{ebiten.KeyArrowLeft}
+	case terminal.KeyRight:
+		return []ebiten.Key{ebiten.KeyArrowRight}
+	case terminal.KeyEnter:
+		return []ebiten.Key{ebiten.KeyEnter}
+	case terminal.KeySpace:
+		return []ebiten.Key{ebiten.KeySpace}
+	case terminal.KeyEscape:
+		return []ebiten.Key{ebiten.KeyEscape}
+	case terminal.KeyBackspace:
+		return []ebiten.Key{ebiten.KeyBackspace}
+	}
+
+	switch r := event.Rune; {
+	case r >= 'a' && r <= 'z':
+		return []ebiten.Key{ebiten.KeyA + ebiten.Key(r-'a')}
+	case r >= 'A' && r <= 'Z':
+		return []ebiten.Key{ebiten.KeyShift, ebiten.KeyA + ebiten.Key(r-'A')}
+	case r >= '0' && r <= '9':
+		return []ebiten.Key{ebiten.KeyDigit0 + ebiten.Key(r-'0')}
+	default:
+		return nil
+	}
+}
+
+// inputFor returns the input of holding down a single direction
+func inputFor(dir sim.Direction) sim.Input {
+	switch dir {
+	case sim.Up:
+		return sim.Input{Up: true}
+	case sim.Right:
+		return sim.Input{Right: true}
+	case sim.Down:
+		return sim.Input{Down: true}
+	default:
+		return sim.Input{Left: true}
+	}
+}
+
+// terminalGauges returns the fuel and deadline bars, for the difficulties that have them
+func (s *session) terminalGauges(cells glyphs) string {
+	const width = 20 // Cells in each bar
+	fuelLabel, deadlineLabel := s.hudLabels()
+
+	var gauges []string
+	for _, gauge := range []struct {
+		label string
+		read  func() (float64, bool)
+	}{
+		{fuelLabel, s.fuelGauge},
+		{deadlineLabel, s.deadlineGauge},
+	} {
+		level, ok := gauge.read()
+		if !ok {
+			continue
+		}
+
+		full := int(math.Ceil(level * width))
+		bar := strings.Repeat(cells.full, full) + strings.Repeat(cells.empty, width-full)
+		if level < 0.25 {
+			bar = terminal.Red + bar + terminal.Reset // Running low
+		}
+		gauges = append(gauges, bar+" "+gauge.label)
+	}
+
+	return strings.Join(gauges, "   ")
+}
+
+// renderTerminal draws a whole frame of the game as text
+func renderTerminal(s *session, titleScreen bool, menu *pauseMenu, cells glyphs, cols, rows int) string {
+	var b strings.Builder
+
+	if titleScreen {
+		b.WriteString(terminal.Bold + strings.ToUpper(Metadata.Title) + terminal.Reset + "\n\n")
+		for _, line := range s.titleScreenLines() {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("Q: Exit at any time\n")
+		return b.String()
+	}
+
+	// Show the pause menu instead of the maze, so it can't be studied while the clock is stopped
+	if menu.open {
+		for i, line := range menu.lines() {
+			if i == 0 {
+				line = terminal.Bold + line + terminal.Reset
+			}
+			b.WriteString(line + "\n")
+		}
+		return b.String()
+	}
+
+	// The maze plus its border, two columns per cell, and room for the status line, gauges and power-ups
+	mazeWidth, mazeHeight := s.world.Size()
+	if cols < (mazeWidth+2)*2 || rows < mazeHeight+6 {
+		return fmt.Sprintf("Make your terminal at least %dx%d to play (it's %dx%d)", (mazeWidth+2)*2, mazeHeight+6, cols, rows)
+	}
+
+	b.WriteString(s.statusLine(menu.settings) + "\n")
+	b.WriteString(s.terminalGauges(cells) + "\n")
+	b.WriteString(s.inventoryLine() + "\n")
+
+	car := s.world.Car()
+	ghost, racing := s.ghostCar()
+	traffic := s.world.Traffic()
+	pickups := make(map[[2]int]sim.PowerUp)
+	for _, pickup := range s.world.PowerUps() {
+		pickups[[2]int{pickup.CellX, pickup.CellY}] = pickup.PowerUp
+	}
+	radar := make(map[int]bool)
+	for _, x := range s.world.Radar() {
+		radar[x] = true
+	}
+	// A shift's packages waiting in the maze, and the customers waiting for them, numbered so they can be matched up
+	packages := make(map[[2]int]string)
+	for i, p := range s.world.Packages() {
+		label := terminal.Bold + packageTerminalColors[i%len(packageTerminalColors)] + fmt.Sprintf("%-2d", i+1)
+		if p.State == sim.Waiting {
+			packages[[2]int{p.CellX, p.CellY}] = label
+		}
+		if p.State != sim.Delivered {
+			x, y := p.Customer(mazeWidth, mazeHeight)
+			packages[[2]int{x, y}] = terminal.Reverse + label
+		}
+	}
+
+	// Walls turn icy while they're frozen, and the ones that shift in a level are dimmed so they can be told apart from
+	// its permanent walls
+	wall := cells.wall
+	if s.world.Frozen() > 0 {
+		wall = terminal.Cyan + strings.TrimPrefix(cells.wall, terminal.White)
+	}
+	level := s.world.Config().Level != nil
+	startX, startY := s.world.Start()
+	endX, endY := s.world.End()
+
+	for y := -1; y <= mazeHeight; y++ {
+		for x := -1; x <= mazeWidth; x++ {
+			var cell string
+			switch {
+			case x == startX && y == startY:
+				cell = cells.start
+			case packages[[2]int{x, y}] != "":
+				cell = packages[[2]int{x, y}]
+			case x == endX && y == endY && !s.world.Shift():
+				cell = cells.end
+			case y == endY && radar[x]:
+				cell = terminal.Blue + cells.radar // Where the customer might go next
+			case x < 0 || x >= mazeWidth || y < 0 || y >= mazeHeight:
+				cell = cells.border
+			case s.world.Wall(x, y) && level && s.world.Permanent(x, y):
+				cell = cells.wall
+			case s.world.Wall(x, y) && level:
+				cell = terminal.Dim + wall
+			case s.world.Wall(x, y):
+				cell = wall
+			case pickups[[2]int{x, y}] != sim.NoPowerUp:
+				powerUp := pickups[[2]int{x, y}]
+				cell = terminal.Bold + powerUpTerminalColors[powerUp] + powerUpName(powerUp)[:1] + " "
+			default:
+				cell = cells.path
+			}
+
+			// Draw the ghost faintly on the streets, the traffic, always inside the maze, and then the car over
+			// whatever cell it's on, keeping the cell's background
+			if racing && x == ghost.CellX && y == ghost.CellY && cell == cells.path {
+				cell = terminal.Dim + cells.car[ghost.Direction]
+			}
+			for _, vehicle := range traffic {
+				if x == vehicle.CellX && y == vehicle.CellY {
+					cell = trafficColors[vehicle.Behavior] + cells.car[vehicle.Direction]
+				}
+			}
+			if x == car.CellX && y == car.CellY {
+				background := ""
+				if cell == cells.start {
+					background = terminal.BgGreen
+				} else if cell == cells.end {
+					background = terminal.BgBlue
+				}
+				cell = background + terminal.Bold + terminal.Red + cells.car[car.Direction]
+			}
+
+			b.WriteString(cell + terminal.Reset)
+		}
+		b.WriteString("\n")
+	}
+
+	if s.finished() {
+		b.WriteString("\n")
+		for _, line := range s.resultLines() {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	if s.scoreSaved {
+		b.WriteString("\n" + s.leaderboardHeadline() + "\n")
+		for i, entry := range s.topScores {
+			row := leaderboardRow(i+1, entry)
+			if i+1 == s.scoreRank {
+				row = terminal.Reverse + row + terminal.Reset
+			}
+			b.WriteString(row + "\n")
+		}
+	}
+
+	return b.String()
+}
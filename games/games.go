@@ -0,0 +1,9 @@
+package games
+
+// Every game must be imported here so that its init() function registers it with the registry.
+// Keep this list sorted, with one game per line.
+
+import (
+	_ "github.com/emmahsax/go-games/games/deliveryDash"
+	_ "github.com/emmahsax/go-games/games/yourGame"
+)
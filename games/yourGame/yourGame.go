@@ -1,9 +1,71 @@
 package yourGame // <----- Change the name of your game here
 
 import (
+	"github.com/emmahsax/go-games/input"
+	"github.com/emmahsax/go-games/registry"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/spf13/cobra"
 )
 
+const (
+	screenWidth  = 640 // Width of the game window
+	screenHeight = 480 // Height of the game window
+)
+
+var Metadata = registry.Metadata{
+	Title:       "Your Game",                        // <----- Change the title of your game here
+	Description: "Your game description goes here.", // <----- Describe your game here
+	Controls: []registry.Control{
+		{Keys: "ESC / Backspace", Action: "Exit at any time"},
+	},
+	MinPlayers: 1,
+	MaxPlayers: 1,
+	Version:    "0.1.0",
+}
+
+// Registers your game so that it shows up as a go-games command
+func init() {
+	registry.Register(registry.Game{
+		NewCommand: NewCommand,
+		Metadata:   Metadata,
+	})
+}
+
+type Game struct {
+	input *input.Handler // Which actions (Up, Confirm, Back, ...) are held, read with Pressed and JustPressed
+
+	// Add the state of your game here
+}
+
+func NewGame(bindings input.Bindings) *Game {
+	return &Game{
+		input: input.NewHandler(bindings),
+	}
+}
+
+func (g *Game) Update() error {
+	g.input.Update()
+
+	// Check for the back action (ESC by default) to exit
+	if g.input.JustPressed(input.Back) {
+		return ebiten.Termination
+	}
+
+	// Update the state of your game here (this is called 60 times per second)
+
+	return nil
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	// Draw your game here
+	ebitenutil.DebugPrint(screen, Metadata.Title+" goes here - Press ESC to exit")
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
+}
+
 func NewCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "your-game", // <----- Change how users call your game here
@@ -15,6 +77,18 @@ func NewCommand() *cobra.Command {
 		// Short:   "",
 
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// Add any actions of your own with input.DefaultBindings().With(...)
+			bindings, err := input.Load(input.DefaultBindings())
+			if err != nil {
+				return err
+			}
+
+			ebiten.SetWindowSize(screenWidth, screenHeight)
+			ebiten.SetWindowTitle(Metadata.Title)
+
+			if err := ebiten.RunGame(NewGame(bindings)); err != nil {
+				return err
+			}
 
 			return nil
 		},
@@ -5,6 +5,7 @@ go 1.24.4
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.8.8
 	github.com/spf13/cobra v1.9.1
+	golang.org/x/sys v0.25.0
 )
 
 require (
@@ -15,5 +16,4 @@ require (
 	github.com/jezek/xgb v1.1.1 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
 	golang.org/x/sync v0.8.0 // indirect
-	golang.org/x/sys v0.25.0 // indirect
 )
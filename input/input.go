@@ -0,0 +1,341 @@
+package input
+
+// A shared input layer so games respond to abstract actions (Up, Confirm, Pause, ...) instead of raw keys. Each
+// action is bound to keys, gamepad buttons and gamepad sticks, and players can rebind any of them in
+// $XDG_CONFIG_HOME/go-games/bindings.json (or ~/.config/go-games/bindings.json), for example:
+//
+//	{
+//	  "up":      {"keys": ["ArrowUp", "K"], "buttons": ["LeftTop"], "axes": ["LeftStickUp"]},
+//	  "confirm": {"keys": ["Enter"]}
+//	}
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/emmahsax/go-games/storage"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	bindingsFile  = "bindings.json" // Name of the file in the configuration directory that players rebind actions in
+	stickDeadZone = 0.5             // How far a stick has to be pushed before it counts as held
+)
+
+// Action is something a player can do, independent of the key or button they do it with. Games can add their own
+// actions next to the shared ones below.
+type Action string
+
+const (
+	Up      Action = "up"
+	Down    Action = "down"
+	Left    Action = "left"
+	Right   Action = "right"
+	Confirm Action = "confirm"
+	Pause   Action = "pause"
+	Back    Action = "back"
+)
+
+// Chord is a set of keys that have to be held down together (e.g. Shift+R), usually just a single key
+type Chord []ebiten.Key
+
+// Stick is one direction of a gamepad stick (e.g. the left stick pushed up)
+type Stick struct {
+	Axis     ebiten.StandardGamepadAxis
+	Positive bool // Whether the stick is pushed toward the positive end of the axis (right or down)
+}
+
+// Binding is every way of doing a single action
+type Binding struct {
+	Keys    []Chord
+	Buttons []ebiten.StandardGamepadButton
+	Sticks  []Stick
+}
+
+// Bindings maps every action to the ways of doing it
+type Bindings map[Action]Binding
+
+// Keys returns a binding of a single key per chord
+func Keys(keys ...ebiten.Key) []Chord {
+	chords := make([]Chord, len(keys))
+	for i, key := range keys {
+		chords[i] = Chord{key}
+	}
+	return chords
+}
+
+// DefaultBindings returns the bindings of the shared actions: arrow keys and WASD, the D-pad and left stick to
+// move, Space or Enter to confirm, ESC or P to pause, and ESC or Backspace to go back
+func DefaultBindings() Bindings {
+	return Bindings{
+		Up: {
+			Keys:    Keys(ebiten.KeyArrowUp, ebiten.KeyW),
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftTop},
+			Sticks:  []Stick{{Axis: ebiten.StandardGamepadAxisLeftStickVertical, Positive: false}},
+		},
+		Down: {
+			Keys:    Keys(ebiten.KeyArrowDown, ebiten.KeyS),
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftBottom},
+			Sticks:  []Stick{{Axis: ebiten.StandardGamepadAxisLeftStickVertical, Positive: true}},
+		},
+		Left: {
+			Keys:    Keys(ebiten.KeyArrowLeft, ebiten.KeyA),
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftLeft},
+			Sticks:  []Stick{{Axis: ebiten.StandardGamepadAxisLeftStickHorizontal, Positive: false}},
+		},
+		Right: {
+			Keys:    Keys(ebiten.KeyArrowRight, ebiten.KeyD),
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftRight},
+			Sticks:  []Stick{{Axis: ebiten.StandardGamepadAxisLeftStickHorizontal, Positive: true}},
+		},
+		Confirm: {
+			Keys:    Keys(ebiten.KeySpace, ebiten.KeyEnter),
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightBottom},
+		},
+		Pause: {
+			Keys:    Keys(ebiten.KeyEscape, ebiten.KeyP),
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonCenterRight},
+		},
+		Back: {
+			Keys:    Keys(ebiten.KeyEscape, ebiten.KeyBackspace),
+			Buttons: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightRight},
+		},
+	}
+}
+
+// With returns a copy of the bindings with more actions added (or replaced), for games that have their own actions
+func (b Bindings) With(extra Bindings) Bindings {
+	merged := make(Bindings, len(b)+len(extra))
+	for action, binding := range b {
+		merged[action] = binding
+	}
+	for action, binding := range extra {
+		merged[action] = binding
+	}
+	return merged
+}
+
+// Load returns the given default bindings with any actions the player rebound in their bindings file replaced
+func Load(defaults Bindings) (Bindings, error) {
+	path, err := storage.ConfigPath(bindingsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var file map[Action]bindingFile
+	if err := storage.Load(path, &file); err != nil {
+		return nil, fmt.Errorf("could not read key bindings from %s: %w", path, err)
+	}
+
+	bindings := defaults.With(nil)
+	for action, raw := range file {
+		binding, err := raw.parse()
+		if err != nil {
+			return nil, fmt.Errorf("invalid binding for %q in %s: %w", action, path, err)
+		}
+		bindings[action] = binding
+	}
+
+	return bindings, nil
+}
+
+// Match returns the actions bound to exactly this chord of keys, sorted by name (e.g. a key press read from a
+// terminal, where Shift+R matches an action bound to Shift+R but not one bound to R)
+func (b Bindings) Match(keys ...ebiten.Key) []Action {
+	var actions []Action
+	for action, binding := range b {
+		for _, chord := range binding.Keys {
+			if sameKeys(chord, keys) {
+				actions = append(actions, action)
+				break
+			}
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+
+	return actions
+}
+
+func sameKeys(a, b []ebiten.Key) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, key := range a {
+		found := false
+		for _, other := range b {
+			if key == other {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Handler tracks the state of every bound action from one tick to the next, giving just-pressed, held and
+// just-released semantics. Call Update once at the start of every tick.
+type Handler struct {
+	bindings Bindings
+	held     map[Action]bool // Actions held this tick
+	previous map[Action]bool // Actions held on the previous tick
+	gamepads []ebiten.GamepadID
+}
+
+func NewHandler(bindings Bindings) *Handler {
+	return &Handler{
+		bindings: bindings,
+		held:     make(map[Action]bool),
+		previous: make(map[Action]bool),
+	}
+}
+
+// Update reads the keyboard and every connected gamepad
+func (h *Handler) Update() {
+	h.previous, h.held = h.held, h.previous
+	h.gamepads = ebiten.AppendGamepadIDs(h.gamepads[:0])
+
+	for action, binding := range h.bindings {
+		h.held[action] = h.isHeld(binding)
+	}
+}
+
+func (h *Handler) isHeld(binding Binding) bool {
+	for _, chord := range binding.Keys {
+		if len(chord) == 0 {
+			continue
+		}
+
+		held := true
+		for _, key := range chord {
+			held = held && ebiten.IsKeyPressed(key)
+		}
+		if held {
+			return true
+		}
+	}
+
+	for _, id := range h.gamepads {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+
+		for _, button := range binding.Buttons {
+			if ebiten.IsStandardGamepadButtonPressed(id, button) {
+				return true
+			}
+		}
+
+		for _, stick := range binding.Sticks {
+			value := ebiten.StandardGamepadAxisValue(id, stick.Axis)
+			if (stick.Positive && value > stickDeadZone) || (!stick.Positive && value < -stickDeadZone) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Pressed reports whether the action is held down this tick
+func (h *Handler) Pressed(action Action) bool {
+	return h.held[action]
+}
+
+// JustPressed reports whether the action started being held down this tick
+func (h *Handler) JustPressed(action Action) bool {
+	return h.held[action] && !h.previous[action]
+}
+
+// JustReleased reports whether the action stopped being held down this tick
+func (h *Handler) JustReleased(action Action) bool {
+	return !h.held[action] && h.previous[action]
+}
+
+// bindingFile is how a binding is written in the bindings file
+type bindingFile struct {
+	Keys    []string `json:"keys,omitempty"`    // Key names, joining keys held together with + (e.g. ArrowUp, Shift+R)
+	Buttons []string `json:"buttons,omitempty"` // Standard gamepad button names (e.g. RightBottom, CenterRight)
+	Axes    []string `json:"axes,omitempty"`    // Stick directions (e.g. LeftStickUp, RightStickLeft)
+}
+
+var buttonNames = map[string]ebiten.StandardGamepadButton{
+	"rightbottom":      ebiten.StandardGamepadButtonRightBottom,
+	"rightright":       ebiten.StandardGamepadButtonRightRight,
+	"rightleft":        ebiten.StandardGamepadButtonRightLeft,
+	"righttop":         ebiten.StandardGamepadButtonRightTop,
+	"fronttopleft":     ebiten.StandardGamepadButtonFrontTopLeft,
+	"fronttopright":    ebiten.StandardGamepadButtonFrontTopRight,
+	"frontbottomleft":  ebiten.StandardGamepadButtonFrontBottomLeft,
+	"frontbottomright": ebiten.StandardGamepadButtonFrontBottomRight,
+	"centerleft":       ebiten.StandardGamepadButtonCenterLeft,
+	"centerright":      ebiten.StandardGamepadButtonCenterRight,
+	"leftstick":        ebiten.StandardGamepadButtonLeftStick,
+	"rightstick":       ebiten.StandardGamepadButtonRightStick,
+	"lefttop":          ebiten.StandardGamepadButtonLeftTop,
+	"leftbottom":       ebiten.StandardGamepadButtonLeftBottom,
+	"leftleft":         ebiten.StandardGamepadButtonLeftLeft,
+	"leftright":        ebiten.StandardGamepadButtonLeftRight,
+	"centercenter":     ebiten.StandardGamepadButtonCenterCenter,
+}
+
+var stickNames = map[string]Stick{
+	"leftstickup":     {Axis: ebiten.StandardGamepadAxisLeftStickVertical, Positive: false},
+	"leftstickdown":   {Axis: ebiten.StandardGamepadAxisLeftStickVertical, Positive: true},
+	"leftstickleft":   {Axis: ebiten.StandardGamepadAxisLeftStickHorizontal, Positive: false},
+	"leftstickright":  {Axis: ebiten.StandardGamepadAxisLeftStickHorizontal, Positive: true},
+	"rightstickup":    {Axis: ebiten.StandardGamepadAxisRightStickVertical, Positive: false},
+	"rightstickdown":  {Axis: ebiten.StandardGamepadAxisRightStickVertical, Positive: true},
+	"rightstickleft":  {Axis: ebiten.StandardGamepadAxisRightStickHorizontal, Positive: false},
+	"rightstickright": {Axis: ebiten.StandardGamepadAxisRightStickHorizontal, Positive: true},
+}
+
+func (f bindingFile) parse() (Binding, error) {
+	var binding Binding
+
+	for _, name := range f.Keys {
+		var chord Chord
+		for _, part := range strings.Split(name, "+") {
+			var key ebiten.Key
+			if err := key.UnmarshalText([]byte(strings.TrimSpace(part))); err != nil {
+				return Binding{}, fmt.Errorf("unknown key %q", part)
+			}
+			chord = append(chord, key)
+		}
+		binding.Keys = append(binding.Keys, chord)
+	}
+
+	for _, name := range f.Buttons {
+		button, ok := buttonNames[strings.ToLower(name)]
+		if !ok {
+			return Binding{}, fmt.Errorf("unknown gamepad button %q", name)
+		}
+		binding.Buttons = append(binding.Buttons, button)
+	}
+
+	for _, name := range f.Axes {
+		stick, ok := stickNames[strings.ToLower(name)]
+		if !ok {
+			return Binding{}, fmt.Errorf("unknown gamepad stick direction %q", name)
+		}
+		binding.Sticks = append(binding.Sticks, stick)
+	}
+
+	return binding, nil
+}
+
+// UnmarshalJSON lets a binding be written as just a list of key names (e.g. "up": ["ArrowUp", "K"])
+func (f *bindingFile) UnmarshalJSON(data []byte) error {
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err == nil {
+		f.Keys = keys
+		return nil
+	}
+
+	type plain bindingFile
+	return json.Unmarshal(data, (*plain)(f))
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/emmahsax/go-games/games/deliveryDash"
-	"github.com/emmahsax/go-games/games/yourGame" // <----- Change the name of your game here
+	"github.com/emmahsax/go-games/catalog"
+	_ "github.com/emmahsax/go-games/games"
+	"github.com/emmahsax/go-games/registry"
+	"github.com/emmahsax/go-games/scaffold"
+	"github.com/emmahsax/go-games/scores"
 	"github.com/spf13/cobra"
 )
 
@@ -17,8 +20,14 @@ func NewCommand() *cobra.Command {
 
 	cmd.DisableAutoGenTag = true
 
-	cmd.AddCommand(deliveryDash.NewCommand())
-	cmd.AddCommand(yourGame.NewCommand()) // <----- Change the name of your game here
+	cmd.AddCommand(catalog.NewListCommand())
+	cmd.AddCommand(catalog.NewInfoCommand())
+	cmd.AddCommand(scaffold.NewCommand())
+	cmd.AddCommand(scores.NewCommand())
+
+	for _, game := range registry.Games() {
+		cmd.AddCommand(game.NewCommand())
+	}
 
 	return cmd
 }
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// Game is a single entry in the registry
+type Game struct {
+	NewCommand func() *cobra.Command // Builds the cobra command that runs the game
+	Metadata   Metadata              // Describes the game for the list and info commands
+
+	name    string   // The name people use on the command-line to call the game, taken from the command's usage line
+	aliases []string // Other names people can use to call the game, taken from the command's aliases
+}
+
+// Metadata describes a game and how to play it
+type Metadata struct {
+	Title       string    // Human readable title (e.g. Delivery Dash)
+	Description string    // A paragraph or two explaining the game
+	Controls    []Control // Every control the game responds to
+	MinPlayers  int       // Fewest players the game supports (defaults to 1)
+	MaxPlayers  int       // Most players the game supports (defaults to MinPlayers)
+	Tags        []string  // Keywords to help people find the game (e.g. maze, puzzle)
+	Author      string    // Who made the game
+	Version     string    // Version of the game, bumped when gameplay changes
+}
+
+// Control is a single input and what it does in the game
+type Control struct {
+	Keys   string // The keys (or buttons) to press (e.g. Arrow keys / WASD)
+	Action string // What pressing them does (e.g. Move one cell)
+}
+
+// Players returns the number of supported players as a string (e.g. 1 or 2-4)
+func (m Metadata) Players() string {
+	if m.MinPlayers == m.MaxPlayers {
+		return fmt.Sprintf("%d", m.MinPlayers)
+	}
+	return fmt.Sprintf("%d-%d", m.MinPlayers, m.MaxPlayers)
+}
+
+// Name returns the name people use on the command-line to call the game (e.g. delivery-dash)
+func (g Game) Name() string {
+	return g.name
+}
+
+// Aliases returns the other names people can use to call the game (e.g. dd)
+func (g Game) Aliases() []string {
+	return g.aliases
+}
+
+var (
+	mu    sync.RWMutex
+	games = make(map[string]Game)
+)
+
+// Register adds a game to the registry, and should be called from the init() function of the game's package
+func Register(game Game) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if game.NewCommand == nil {
+		panic("registry: game has no command")
+	}
+
+	cmd := game.NewCommand()
+	game.name = cmd.Name()
+	game.aliases = cmd.Aliases
+	if game.name == "" {
+		panic("registry: game command has no usage line")
+	}
+	if _, exists := games[game.name]; exists {
+		panic(fmt.Sprintf("registry: game %q is already registered", game.name))
+	}
+
+	// Fill in sensible defaults for anything the game left out
+	if game.Metadata.Title == "" {
+		game.Metadata.Title = game.name
+	}
+	if game.Metadata.Description == "" {
+		game.Metadata.Description = cmd.Short
+	}
+	if game.Metadata.MinPlayers < 1 {
+		game.Metadata.MinPlayers = 1
+	}
+	if game.Metadata.MaxPlayers < game.Metadata.MinPlayers {
+		game.Metadata.MaxPlayers = game.Metadata.MinPlayers
+	}
+
+	games[game.name] = game
+}
+
+// Games returns every registered game, sorted by name
+func Games() []Game {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	list := make([]Game, 0, len(games))
+	for _, game := range games {
+		list = append(list, game)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+
+	return list
+}
+
+// Lookup finds a registered game by its name or one of its aliases
+func Lookup(name string) (Game, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if game, ok := games[name]; ok {
+		return game, true
+	}
+
+	for _, game := range games {
+		for _, alias := range game.aliases {
+			if alias == name {
+				return game, true
+			}
+		}
+	}
+
+	return Game{}, false
+}
@@ -0,0 +1,324 @@
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+const modulePath = "github.com/emmahsax/go-games" // Module that new games are created in
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// Name holds the different spellings of a new game's name
+type Name struct {
+	Package string // Go package and directory name (e.g. superFunTimeGame)
+	Command string // Name people use on the command-line (e.g. super-fun-time-game)
+	Alias   string // Short alias for the command, empty when there isn't a free one (e.g. sftg)
+	Title   string // Human readable title (e.g. Super Fun Time Game)
+	Short   string // Brief description of the game shown in the command's help
+	About   string // Longer description of the game for its metadata
+	Author  string // Who made the game
+}
+
+// ParseName splits a game name written in kebab-case, snake_case, camelCase or with spaces into its different spellings
+func ParseName(raw string) (Name, error) {
+	words := splitWords(raw)
+	if len(words) == 0 {
+		return Name{}, errors.New("game name must not be empty")
+	}
+
+	for _, word := range words {
+		for _, r := range word {
+			if r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r)) {
+				return Name{}, fmt.Errorf("game name %q may only contain letters, digits, dashes, underscores and spaces", raw)
+			}
+		}
+	}
+	if !unicode.IsLetter(rune(words[0][0])) {
+		return Name{}, fmt.Errorf("game name %q must start with a letter", raw)
+	}
+
+	var pkg, title, alias strings.Builder
+	for i, word := range words {
+		if i == 0 {
+			pkg.WriteString(word)
+		} else {
+			pkg.WriteString(strings.ToUpper(word[:1]) + word[1:])
+			title.WriteString(" ")
+		}
+		title.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		alias.WriteByte(word[0])
+	}
+
+	name := Name{
+		Package: pkg.String(),
+		Command: strings.Join(words, "-"),
+		Title:   title.String(),
+	}
+	if len(words) > 1 {
+		name.Alias = alias.String()
+	}
+
+	if !token.IsIdentifier(name.Package) {
+		return Name{}, fmt.Errorf("game name %q is not a valid Go package name", raw)
+	}
+	if token.IsKeyword(name.Package) {
+		return Name{}, fmt.Errorf("game name %q is a Go keyword, so it can't be a package name", raw)
+	}
+	// A package named after a predeclared identifier would hide it from every file that imports the game
+	if types.Universe.Lookup(name.Package) != nil {
+		return Name{}, fmt.Errorf("game name %q is a predeclared Go identifier (like string or len), so it can't be a package name", raw)
+	}
+	if name.Package == "main" || name.Package == "games" || name.Package == "registry" {
+		return Name{}, fmt.Errorf("game name %q is reserved", raw)
+	}
+
+	return name, nil
+}
+
+// splitWords breaks a name apart on dashes, underscores, spaces and camelCase boundaries, and lowercases every word
+func splitWords(raw string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	runes := []rune(strings.TrimSpace(raw))
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == '_' || unicode.IsSpace(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	return words
+}
+
+// Create writes a new game package into the games directory of the repository at root and registers it in games/games.go.
+// If any of it can't be written, the game's directory is removed again rather than left half made.
+func Create(root string, name Name) (created []string, err error) {
+	dir := filepath.Join(root, "games", name.Package)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("%s already exists, refusing to overwrite it", dir)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	files := map[string]string{
+		"game.go.tmpl":      filepath.Join(dir, name.Package+".go"),
+		"game_test.go.tmpl": filepath.Join(dir, name.Package+"_test.go"),
+	}
+
+	rendered := make(map[string][]byte, len(files))
+	for tmpl, path := range files {
+		source, err := render(tmpl, name)
+		if err != nil {
+			return nil, err
+		}
+		rendered[path] = source
+	}
+
+	gamesFile := filepath.Join(root, "games", "games.go")
+	imports, err := addImport(gamesFile, modulePath+"/games/"+name.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	created = make([]string, 0, len(rendered))
+	for path, source := range rendered {
+		if err := os.WriteFile(path, source, 0o644); err != nil {
+			return nil, err
+		}
+		created = append(created, path)
+	}
+	sort.Strings(created)
+
+	if err := os.WriteFile(gamesFile, imports, 0o644); err != nil {
+		return nil, err
+	}
+
+	return append(created, gamesFile), nil
+}
+
+// checkAvailable makes sure no existing command already uses the new game's name, and drops the alias if it's taken
+func checkAvailable(root *cobra.Command, name *Name) error {
+	for _, cmd := range root.Commands() {
+		if cmd.Name() == name.Command || cmd.HasAlias(name.Command) {
+			return fmt.Errorf("a command called %q already exists", name.Command)
+		}
+		if cmd.Name() == name.Alias || cmd.HasAlias(name.Alias) {
+			name.Alias = ""
+		}
+	}
+
+	return nil
+}
+
+func render(name string, data Name) ([]byte, error) {
+	tmpl, err := template.ParseFS(templates, "templates/"+name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// addImport returns the contents of games/games.go with a blank import of pkg added to the sorted import list
+func addImport(path, pkg string) ([]byte, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	line := fmt.Sprintf("\t_ %q", pkg)
+
+	var before, imports, after []string
+	section := &before
+
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		switch {
+		case section == &before && strings.TrimSpace(text) == "import (":
+			before = append(before, text)
+			section = &imports
+			continue
+		case section == &imports && strings.TrimSpace(text) == ")":
+			section = &after
+		}
+
+		*section = append(*section, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if section != &after {
+		return nil, fmt.Errorf("could not find the import list in %s", path)
+	}
+
+	for _, existing := range imports {
+		if strings.TrimSpace(existing) == strings.TrimSpace(line) {
+			return nil, fmt.Errorf("%s already imports %s", path, pkg)
+		}
+	}
+	imports = append(imports, line)
+	sort.Strings(imports)
+
+	lines := append(append(before, imports...), after...)
+	return format.Source([]byte(strings.Join(lines, "\n") + "\n"))
+}
+
+// findRoot walks up from the working directory until it finds the go-games go.mod file
+func findRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		mod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil && strings.HasPrefix(string(mod), "module "+modulePath+"\n") {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("could not find the go-games repository, run this command from inside it")
+		}
+		dir = parent
+	}
+}
+
+func NewCommand() *cobra.Command {
+	var short, author string
+
+	cmd := &cobra.Command{
+		Use:   "new <name>",
+		Short: "Create a new game from the yourGame template (e.g. go-games new super-fun-time-game)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := ParseName(args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkAvailable(cmd.Root(), &name); err != nil {
+				return err
+			}
+			name.Author = author
+			name.About = short
+			if name.About == "" {
+				name.About = fmt.Sprintf("Play %s!", name.Title)
+			}
+			name.Short = name.About
+			if name.Alias != "" {
+				name.Short += fmt.Sprintf(" (alias: %s)", name.Alias)
+			}
+
+			root, err := findRoot()
+			if err != nil {
+				return err
+			}
+
+			created, err := Create(root, name)
+			if err != nil {
+				return err
+			}
+
+			for _, path := range created {
+				if rel, err := filepath.Rel(root, path); err == nil {
+					path = rel
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", path)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "\nRun your game with: go run main.go %s\n", name.Command)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&short, "short", "", "Brief description of the game")
+	cmd.Flags().StringVar(&author, "author", "", "Who made the game")
+
+	return cmd
+}
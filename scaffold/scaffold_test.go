@@ -0,0 +1,133 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gamesFile is the games/games.go a new game gets registered in
+const gamesFile = `package games
+
+// Every game must be imported here so that its init() function registers it with the registry.
+// Keep this list sorted, with one game per line.
+
+import (
+	_ "github.com/emmahsax/go-games/games/deliveryDash"
+	_ "github.com/emmahsax/go-games/games/yourGame"
+)
+`
+
+// newRoot returns a repository with nothing but a games/games.go file in it
+func newRoot(t *testing.T, games string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "games"), 0o755); err != nil {
+		t.Fatalf("expected the games directory to be made, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "games", "games.go"), []byte(games), 0o644); err != nil {
+		t.Fatalf("expected games.go to be written, got %v", err)
+	}
+	return root
+}
+
+func TestParseNameSpellings(t *testing.T) {
+	name, err := ParseName("super fun-time_game")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := Name{Package: "superFunTimeGame", Command: "super-fun-time-game", Alias: "sftg", Title: "Super Fun Time Game"}
+	if name != want {
+		t.Errorf("expected %+v, got %+v", want, name)
+	}
+}
+
+func TestParseNameRejectsReservedNames(t *testing.T) {
+	for _, raw := range []string{"", "2048", "func", "Range", "string", "len", "nil", "any", "main", "Main", "registry"} {
+		if _, err := ParseName(raw); err == nil {
+			t.Errorf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+func TestAddImportKeepsImportsSorted(t *testing.T) {
+	root := newRoot(t, gamesFile)
+	source, err := addImport(filepath.Join(root, "games", "games.go"), modulePath+"/games/mazeRunner")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := strings.Replace(gamesFile, "deliveryDash\"\n", "deliveryDash\"\n\t_ \"github.com/emmahsax/go-games/games/mazeRunner\"\n", 1)
+	if string(source) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, source)
+	}
+
+	if _, err := addImport(filepath.Join(root, "games", "games.go"), modulePath+"/games/yourGame"); err == nil {
+		t.Errorf("expected importing a game twice to be rejected")
+	}
+}
+
+func TestCreateWritesGame(t *testing.T) {
+	root := newRoot(t, gamesFile)
+	name, err := ParseName("maze runner")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	created, err := Create(root, name)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "games", "mazeRunner", "mazeRunner.go"),
+		filepath.Join(root, "games", "mazeRunner", "mazeRunner_test.go"),
+		filepath.Join(root, "games", "games.go"),
+	}
+	if strings.Join(created, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected %v to be written, got %v", want, created)
+	}
+
+	for _, path := range created[:2] {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected %s to be written, got %v", path, err)
+		}
+		if !strings.HasPrefix(string(source), "package mazeRunner\n") || !strings.Contains(string(source), `"maze-runner"`) {
+			t.Errorf("expected %s to be the mazeRunner package with the maze-runner command, got:\n%s", path, source)
+		}
+	}
+
+	games, err := os.ReadFile(created[2])
+	if err != nil {
+		t.Fatalf("expected games.go to be readable, got %v", err)
+	}
+	imports := []string{"games/deliveryDash", "games/mazeRunner", "games/yourGame"}
+	last := -1
+	for _, pkg := range imports {
+		i := strings.Index(string(games), pkg)
+		if i < 0 || i < last {
+			t.Fatalf("expected games.go to import %v in order, got:\n%s", imports, games)
+		}
+		last = i
+	}
+
+	if _, err := Create(root, name); err == nil {
+		t.Errorf("expected creating the same game twice to be rejected")
+	}
+}
+
+func TestCreateLeavesNothingBehindOnFailure(t *testing.T) {
+	root := newRoot(t, "package games\n") // No import list to register the game in
+	name, err := ParseName("maze runner")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := Create(root, name); err == nil {
+		t.Fatalf("expected a games.go without an import list to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(root, "games", "mazeRunner")); !os.IsNotExist(err) {
+		t.Errorf("expected no game directory to be left behind, got %v", err)
+	}
+}
@@ -0,0 +1,346 @@
+package scores
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/emmahsax/go-games/registry"
+	"github.com/emmahsax/go-games/storage"
+	"github.com/spf13/cobra"
+)
+
+const (
+	MaxEntries = 10 // Number of entries kept on each leaderboard
+	MaxGhosts  = 20 // Number of seeds each player keeps a ghost on, the ones they raced most recently
+)
+
+// Entry is a single finished run on a leaderboard
+type Entry struct {
+	Player     string          `json:"player"`
+	Date       time.Time       `json:"date"`
+	Time       time.Duration   `json:"time,omitempty"`       // Time taken, for games where faster is better
+	Score      int             `json:"score,omitempty"`      // Points earned, for games where higher is better
+	Moves      int             `json:"moves,omitempty"`      // Number of moves made during the run
+	Seed       int64           `json:"seed"`                 // Seed the run was played with
+	Difficulty string          `json:"difficulty,omitempty"` // Difficulty the run was played on, each has its own leaderboard
+	Ghost      json.RawMessage `json:"ghost,omitempty"`      // The game's own recording of the run, for racing against it later
+}
+
+// better reports whether a ranks above b, by the highest score and then the fastest time
+func (a Entry) better(b Entry) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Time < b.Time
+}
+
+// Board holds every leaderboard of a single game, one per difficulty. Besides the top MaxEntries of each
+// leaderboard, it also keeps every player's own best entry so that personal bests are never lost, and their best entry
+// with a ghost on each of the MaxGhosts seeds they raced most recently so that those can be raced again.
+type Board struct {
+	Game    string  `json:"game"`
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads the leaderboards of a game, returning empty leaderboards if it has never been played
+func Load(game string) (*Board, error) {
+	path, err := boardPath(game)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &Board{Game: game}
+	if err := storage.Load(path, board); err != nil {
+		return nil, fmt.Errorf("could not read scores for %s: %w", game, err)
+	}
+
+	return board, nil
+}
+
+// Save writes the leaderboards of the game to disk
+func (b *Board) Save() error {
+	path, err := boardPath(b.Game)
+	if err != nil {
+		return err
+	}
+
+	return storage.Save(path, b)
+}
+
+// Add puts an entry on the leaderboard of its difficulty, returning its rank (starting at 1), or 0 if it didn't
+// make the top MaxEntries
+func (b *Board) Add(entry Entry) int {
+	top := b.Top(entry.Difficulty)
+
+	rank := len(top) + 1
+	for i, existing := range top {
+		if entry.better(existing) {
+			rank = i + 1
+			break
+		}
+	}
+
+	b.Entries = append(b.Entries, entry)
+	b.trim(entry.Difficulty)
+
+	if rank > MaxEntries {
+		return 0
+	}
+	return rank
+}
+
+// Top returns the top MaxEntries of the leaderboard of a difficulty, best entry first
+func (b *Board) Top(difficulty string) []Entry {
+	top := b.ranked(difficulty)
+	if len(top) > MaxEntries {
+		top = top[:MaxEntries]
+	}
+
+	return top
+}
+
+// PersonalBest returns a player's best entry on the leaderboard of a difficulty, and false if they don't have one
+func (b *Board) PersonalBest(player, difficulty string) (Entry, bool) {
+	for _, entry := range b.ranked(difficulty) {
+		if entry.Player == player {
+			return entry, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+// SeedBest returns a player's best entry with a ghost on the leaderboard of a difficulty that was played with a seed,
+// and false if they've never recorded one
+func (b *Board) SeedBest(player, difficulty string, seed int64) (Entry, bool) {
+	for _, entry := range b.ranked(difficulty) {
+		if entry.Player == player && entry.Seed == seed && len(entry.Ghost) > 0 {
+			return entry, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+// ranked returns every kept entry of a difficulty, best entry first
+func (b *Board) ranked(difficulty string) []Entry {
+	var ranked []Entry
+	for _, entry := range b.Entries {
+		if entry.Difficulty == difficulty {
+			ranked = append(ranked, entry)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].better(ranked[j])
+	})
+
+	return ranked
+}
+
+// Best returns the best entry on the leaderboard of a difficulty, and false if there isn't one yet
+func (b *Board) Best(difficulty string) (Entry, bool) {
+	top := b.Top(difficulty)
+	if len(top) == 0 {
+		return Entry{}, false
+	}
+	return top[0], true
+}
+
+// Difficulties returns every difficulty that has a leaderboard, sorted by name
+func (b *Board) Difficulties() []string {
+	seen := make(map[string]bool)
+	var difficulties []string
+	for _, entry := range b.Entries {
+		if !seen[entry.Difficulty] {
+			seen[entry.Difficulty] = true
+			difficulties = append(difficulties, entry.Difficulty)
+		}
+	}
+	sort.Strings(difficulties)
+
+	return difficulties
+}
+
+// trim drops everything from the leaderboard of a difficulty that's not in the top MaxEntries, a player's personal
+// best, or a player's best with a ghost on one of the MaxGhosts seeds they raced most recently
+func (b *Board) trim(difficulty string) {
+	var entries []Entry
+	for _, entry := range b.Entries {
+		if entry.Difficulty != difficulty {
+			entries = append(entries, entry)
+		}
+	}
+
+	type playerSeed struct {
+		player string
+		seed   int64
+	}
+
+	// Find the seeds each player raced most recently with a ghost, so the ghosts kept don't grow without end
+	ranked := b.ranked(difficulty)
+	latest := append([]Entry(nil), ranked...)
+	sort.SliceStable(latest, func(i, j int) bool {
+		return latest[i].Date.After(latest[j].Date)
+	})
+	recent := make(map[playerSeed]bool)
+	ghosts := make(map[string]int)
+	for _, entry := range latest {
+		seed := playerSeed{entry.Player, entry.Seed}
+		if len(entry.Ghost) == 0 || recent[seed] || ghosts[entry.Player] == MaxGhosts {
+			continue
+		}
+		recent[seed] = true
+		ghosts[entry.Player]++
+	}
+
+	players := make(map[string]bool)
+	seeds := make(map[playerSeed]bool)
+	for i, entry := range ranked {
+		seed := playerSeed{entry.Player, entry.Seed}
+		if i < MaxEntries || !players[entry.Player] || (len(entry.Ghost) > 0 && recent[seed] && !seeds[seed]) {
+			entries = append(entries, entry)
+		}
+		players[entry.Player] = true
+		if len(entry.Ghost) > 0 {
+			seeds[seed] = true
+		}
+	}
+
+	b.Entries = entries
+}
+
+// Reset deletes every leaderboard of a game
+func Reset(game string) error {
+	path, err := boardPath(game)
+	if err != nil {
+		return err
+	}
+
+	return storage.Remove(path)
+}
+
+func boardPath(game string) (string, error) {
+	return storage.Path("scores", game+".json")
+}
+
+// DefaultPlayer returns the name to record scores under when the player doesn't pick one
+func DefaultPlayer() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		// Windows usernames include the domain (e.g. DOMAIN\name)
+		name := u.Username
+		if i := strings.LastIndex(name, `\`); i >= 0 {
+			name = name[i+1:]
+		}
+		return name
+	}
+
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+
+	return "player"
+}
+
+// FormatTime formats a run's time the same way everywhere (e.g. 12.34s)
+func FormatTime(d time.Duration) string {
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// Print writes the leaderboard of a difficulty as a table
+func Print(out io.Writer, entries []Entry) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "RANK\tPLAYER\tTIME\tSCORE\tMOVES\tSEED\tDATE")
+	for i, entry := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%d\t%s\n",
+			i+1,
+			entry.Player,
+			FormatTime(entry.Time),
+			entry.Score,
+			entry.Moves,
+			entry.Seed,
+			entry.Date.Local().Format("2006-01-02 15:04"),
+		)
+	}
+
+	return w.Flush()
+}
+
+func NewCommand() *cobra.Command {
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:   "scores [game]",
+		Short: "Show the leaderboards of every game, or of a single game",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var games []registry.Game
+			if len(args) == 1 {
+				game, ok := registry.Lookup(args[0])
+				if !ok {
+					return fmt.Errorf("unknown game %q, run go-games list to see every game", args[0])
+				}
+				games = append(games, game)
+			} else if reset {
+				return fmt.Errorf("choose which game to reset (e.g. go-games scores delivery-dash --reset)")
+			} else {
+				games = registry.Games()
+			}
+
+			out := cmd.OutOrStdout()
+
+			if reset {
+				if err := Reset(games[0].Name()); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Reset the leaderboards of %s\n", games[0].Metadata.Title)
+				return nil
+			}
+
+			printed := false
+			for _, game := range games {
+				board, err := Load(game.Name())
+				if err != nil {
+					return err
+				}
+				if len(board.Entries) == 0 {
+					continue
+				}
+
+				for _, difficulty := range board.Difficulties() {
+					if printed {
+						fmt.Fprintln(out)
+					}
+					printed = true
+
+					title := game.Metadata.Title
+					if difficulty != "" {
+						title += " (" + difficulty + ")"
+					}
+					fmt.Fprintln(out, title)
+					if err := Print(out, board.Top(difficulty)); err != nil {
+						return err
+					}
+				}
+			}
+
+			if !printed {
+				fmt.Fprintln(out, "No scores yet, go play a game!")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&reset, "reset", false, "Delete every score of the game")
+
+	return cmd
+}
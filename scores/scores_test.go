@@ -0,0 +1,73 @@
+package scores
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTrimKeepsBestGhostOnEachSeed(t *testing.T) {
+	b := &Board{Game: "test"}
+	ghost := json.RawMessage(`[[0,1,2,3]]`)
+
+	// A player races a few seeds, then others fill the top of the leaderboard and push them off it
+	for seed := int64(1); seed <= 3; seed++ {
+		b.Add(Entry{Player: "racer", Seed: seed, Time: time.Duration(20+seed) * time.Second, Ghost: ghost})
+		b.Add(Entry{Player: "racer", Seed: seed, Time: time.Duration(30+seed) * time.Second, Ghost: ghost})
+	}
+	for i := 0; i < MaxEntries; i++ {
+		b.Add(Entry{Player: fmt.Sprint("player", i), Seed: 100, Time: time.Duration(i+1) * time.Second})
+	}
+
+	for seed := int64(1); seed <= 3; seed++ {
+		best, ok := b.SeedBest("racer", "", seed)
+		if !ok {
+			t.Fatalf("expected a ghost on seed %d to be kept", seed)
+		}
+		if want := time.Duration(20+seed) * time.Second; best.Time != want {
+			t.Errorf("expected the best on seed %d to take %v, got %v", seed, want, best.Time)
+		}
+	}
+	if len(b.Entries) != MaxEntries+3 {
+		t.Errorf("expected the top %d and the racer's best on each seed, got %d entries", MaxEntries, len(b.Entries))
+	}
+}
+
+func TestTrimDropsSlowerRunsWithoutGhosts(t *testing.T) {
+	b := &Board{Game: "test"}
+	for i := 0; i < MaxEntries; i++ {
+		b.Add(Entry{Player: fmt.Sprint("player", i), Seed: int64(i), Time: time.Duration(i+1) * time.Second})
+	}
+	b.Add(Entry{Player: "player0", Seed: 50, Time: time.Minute})
+
+	if len(b.Entries) != MaxEntries {
+		t.Errorf("expected %d entries, got %d", MaxEntries, len(b.Entries))
+	}
+	if _, ok := b.SeedBest("player0", "", 0); ok {
+		t.Errorf("expected no ghost on a seed raced without one")
+	}
+}
+
+func TestTrimKeepsGhostsOnRecentSeeds(t *testing.T) {
+	b := &Board{Game: "test"}
+	ghost := json.RawMessage(`[[0,1,2,3]]`)
+	for i := 0; i < MaxEntries; i++ {
+		b.Add(Entry{Player: fmt.Sprint("player", i), Seed: 100, Time: time.Duration(i+1) * time.Second})
+	}
+
+	// The racer's first runs are their best, so only how long ago they were raced can drop them
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for seed := int64(0); seed < MaxGhosts+2; seed++ {
+		b.Add(Entry{Player: "racer", Date: start.Add(time.Duration(seed) * time.Hour), Seed: seed, Time: time.Duration(20+seed) * time.Second, Ghost: ghost})
+	}
+
+	for seed := int64(0); seed < MaxGhosts+2; seed++ {
+		if _, ok := b.SeedBest("racer", "", seed); ok != (seed == 0 || seed >= 2) {
+			t.Errorf("expected a ghost on seed %d to be kept to be %v, got %v", seed, seed == 0 || seed >= 2, ok)
+		}
+	}
+	if len(b.Entries) != MaxEntries+MaxGhosts+1 {
+		t.Errorf("expected the top %d, the racer's personal best and their %d most recent ghosts, got %d entries", MaxEntries, MaxGhosts, len(b.Entries))
+	}
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const appName = "go-games" // Name of the directory every game's data is kept in
+
+// Dir returns the directory go-games keeps its data in, following the XDG base directory specification
+// ($XDG_DATA_HOME/go-games, falling back to ~/.local/share/go-games, or %LOCALAPPDATA%\go-games on Windows)
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, appName), nil
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "share", appName), nil
+}
+
+// ConfigDir returns the directory go-games keeps its configuration in, following the XDG base directory specification
+// ($XDG_CONFIG_HOME/go-games, falling back to ~/.config/go-games, or %APPDATA%\go-games on Windows)
+func ConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, appName), nil
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", appName), nil
+}
+
+// ConfigPath returns the path of a file inside the configuration directory (e.g. ConfigPath("bindings.json"))
+func ConfigPath(elem ...string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(append([]string{dir}, elem...)...), nil
+}
+
+// Path returns the path of a file inside the data directory (e.g. Path("scores", "delivery-dash.json"))
+func Path(elem ...string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(append([]string{dir}, elem...)...), nil
+}
+
+// Load decodes the JSON file at path into v, leaving v untouched and returning no error if the file doesn't exist yet
+func Load(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Save encodes v as JSON into the file at path, creating any missing directories. The file is written to a
+// temporary file first and then renamed, so a crash never leaves half a file behind.
+func Save(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Remove deletes the file at path, returning no error if it doesn't exist
+func Remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package terminal
+
+import (
+	"errors"
+	"os"
+	"runtime"
+)
+
+var errUnsupported = errors.New("terminal mode is not supported on " + runtime.GOOS)
+
+type state struct{}
+
+func makeRaw(in, out *os.File) (*state, error) {
+	return nil, errUnsupported
+}
+
+func restore(in, out *os.File, st *state) error {
+	return errUnsupported
+}
+
+func size(out *os.File) (int, int, error) {
+	return 0, 0, errUnsupported
+}
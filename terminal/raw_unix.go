@@ -0,0 +1,50 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package terminal
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+type state struct {
+	termios unix.Termios
+}
+
+func makeRaw(in, out *os.File) (*state, error) {
+	termios, err := unix.IoctlGetTermios(int(in.Fd()), ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	old := &state{termios: *termios}
+
+	// The same settings as cfmakeraw(3), so keys arrive one at a time without being echoed
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+
+	if err := unix.IoctlSetTermios(int(in.Fd()), ioctlWriteTermios, termios); err != nil {
+		return nil, err
+	}
+
+	return old, nil
+}
+
+func restore(in, out *os.File, st *state) error {
+	return unix.IoctlSetTermios(int(in.Fd()), ioctlWriteTermios, &st.termios)
+}
+
+func size(out *os.File) (int, int, error) {
+	ws, err := unix.IoctlGetWinsize(int(out.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(ws.Col), int(ws.Row), nil
+}
@@ -0,0 +1,54 @@
+package terminal
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+type state struct {
+	inMode, outMode uint32
+}
+
+func makeRaw(in, out *os.File) (*state, error) {
+	var old state
+	if err := windows.GetConsoleMode(windows.Handle(in.Fd()), &old.inMode); err != nil {
+		return nil, err
+	}
+	if err := windows.GetConsoleMode(windows.Handle(out.Fd()), &old.outMode); err != nil {
+		return nil, err
+	}
+
+	// Read keys one at a time as the same escape sequences other terminals send, without echoing them
+	inMode := old.inMode &^ (windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT)
+	inMode |= windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(windows.Handle(in.Fd()), inMode); err != nil {
+		return nil, err
+	}
+
+	// Understand ANSI escape codes when drawing
+	outMode := old.outMode | windows.ENABLE_PROCESSED_OUTPUT | windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING
+	if err := windows.SetConsoleMode(windows.Handle(out.Fd()), outMode); err != nil {
+		windows.SetConsoleMode(windows.Handle(in.Fd()), old.inMode)
+		return nil, err
+	}
+
+	return &old, nil
+}
+
+func restore(in, out *os.File, st *state) error {
+	if err := windows.SetConsoleMode(windows.Handle(in.Fd()), st.inMode); err != nil {
+		return err
+	}
+
+	return windows.SetConsoleMode(windows.Handle(out.Fd()), st.outMode)
+}
+
+func size(out *os.File) (int, int, error) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(out.Fd()), &info); err != nil {
+		return 0, 0, err
+	}
+
+	return int(info.Window.Right-info.Window.Left) + 1, int(info.Window.Bottom-info.Window.Top) + 1, nil
+}
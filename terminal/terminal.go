@@ -0,0 +1,209 @@
+package terminal
+
+// A tiny terminal backend for games that should also be playable without a window (e.g. over SSH). It puts the
+// terminal into raw mode, switches to the alternate screen, turns key presses into Keys, and has a few helpers for
+// drawing with ANSI escape codes.
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// ANSI escape codes used to draw on the terminal
+const (
+	Reset        = "\x1b[0m"
+	Bold         = "\x1b[1m"
+	Dim          = "\x1b[2m"
+	Reverse      = "\x1b[7m"
+	Red          = "\x1b[31m"
+	Green        = "\x1b[32m"
+	Yellow       = "\x1b[33m"
+	Blue         = "\x1b[34m"
+	Magenta      = "\x1b[35m"
+	Cyan         = "\x1b[36m"
+	White        = "\x1b[37m"
+	BgRed        = "\x1b[41m"
+	BgGreen      = "\x1b[42m"
+	BgYellow     = "\x1b[43m"
+	BgBlue       = "\x1b[44m"
+	BgGray       = "\x1b[100m"
+	ClearLine    = "\x1b[K"
+	clearScreen  = "\x1b[2J"
+	home         = "\x1b[H"
+	hideCursor   = "\x1b[?25l"
+	showCursor   = "\x1b[?25h"
+	altScreen    = "\x1b[?1049h"
+	normalScreen = "\x1b[?1049l"
+)
+
+// Key is a single key press read from the terminal
+type Key int
+
+const (
+	KeyNone Key = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeySpace
+	KeyEscape
+	KeyBackspace
+	KeyInterrupt // Ctrl+C, which raw mode turns into a normal key press
+	KeyRune      // Any other printable character, stored alongside the key
+)
+
+// Event is a key press, with the character that was typed for KeyRune
+type Event struct {
+	Key  Key
+	Rune rune
+}
+
+// Terminal is a terminal in raw mode, switched to the alternate screen
+type Terminal struct {
+	in    *os.File
+	out   *os.File
+	state *state // Terminal settings to restore on Close
+	keys  chan Event
+}
+
+// Open puts the terminal into raw mode, switches to the alternate screen, and starts reading key presses
+func Open() (*Terminal, error) {
+	in, out := os.Stdin, os.Stdout
+
+	st, err := makeRaw(in, out)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Terminal{
+		in:    in,
+		out:   out,
+		state: st,
+		keys:  make(chan Event, 32),
+	}
+
+	io.WriteString(out, altScreen+hideCursor+clearScreen)
+	go t.readKeys()
+
+	return t, nil
+}
+
+// Close restores the terminal to how it was before Open
+func (t *Terminal) Close() error {
+	io.WriteString(t.out, Reset+showCursor+normalScreen)
+	return restore(t.in, t.out, t.state)
+}
+
+// Keys returns the key presses read from the terminal, which is closed when input ends
+func (t *Terminal) Keys() <-chan Event {
+	return t.keys
+}
+
+// Size returns the number of columns and rows of the terminal
+func (t *Terminal) Size() (int, int, error) {
+	return size(t.out)
+}
+
+// Draw replaces everything on the screen with frame, whose lines are separated by newlines. Lines that don't fit on
+// the screen are left off, since writing past the bottom would scroll the screen.
+func (t *Terminal) Draw(frame string) error {
+	lines := strings.Split(strings.TrimSuffix(frame, "\n"), "\n")
+	if _, rows, err := t.Size(); err == nil && len(lines) > rows {
+		lines = lines[:rows]
+	}
+
+	// Raw mode doesn't turn \n into \r\n, so every line has to go back to the first column itself
+	for i := range lines {
+		lines[i] += Reset + ClearLine
+	}
+
+	_, err := io.WriteString(t.out, home+strings.Join(lines, "\r\n")+"\x1b[J")
+	return err
+}
+
+func (t *Terminal) readKeys() {
+	defer close(t.keys)
+
+	buf := make([]byte, 64)
+	for {
+		n, err := t.in.Read(buf)
+		for _, event := range parseKeys(buf[:n]) {
+			t.keys <- event
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseKeys turns the bytes of a single read into key presses, understanding the escape sequences terminals send
+// for the arrow keys
+func parseKeys(b []byte) []Event {
+	var events []Event
+
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == 0x1b && i+2 < len(b) && (b[i+1] == '[' || b[i+1] == 'O'):
+			switch b[i+2] {
+			case 'A':
+				events = append(events, Event{Key: KeyUp})
+			case 'B':
+				events = append(events, Event{Key: KeyDown})
+			case 'C':
+				events = append(events, Event{Key: KeyRight})
+			case 'D':
+				events = append(events, Event{Key: KeyLeft})
+			}
+			i += 2
+
+			// Skip the rest of longer sequences (e.g. \x1b[1;2A for Shift+Up)
+			for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+				i++
+			}
+		case c == 0x1b:
+			events = append(events, Event{Key: KeyEscape})
+		case c == 0x7f || c == 0x08:
+			events = append(events, Event{Key: KeyBackspace})
+		case c == 0x03:
+			events = append(events, Event{Key: KeyInterrupt})
+		case c == '\r' || c == '\n':
+			events = append(events, Event{Key: KeyEnter})
+		case c == ' ':
+			events = append(events, Event{Key: KeySpace})
+		case c >= 0x21 && c < 0x7f:
+			events = append(events, Event{Key: KeyRune, Rune: rune(c)})
+		}
+	}
+
+	return events
+}
+
+// HasDisplay reports whether a graphical display is likely to be available to open a window on
+func HasDisplay() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		// There's always a display, unless we're being used over SSH
+		return os.Getenv("SSH_CONNECTION") == "" && os.Getenv("SSH_TTY") == ""
+	default:
+		return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	}
+}
+
+// SupportsUnicode reports whether the terminal is likely to be able to draw Unicode block and arrow characters
+func SupportsUnicode() bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToUpper(value)
+			return strings.Contains(value, "UTF-8") || strings.Contains(value, "UTF8")
+		}
+	}
+
+	return false
+}